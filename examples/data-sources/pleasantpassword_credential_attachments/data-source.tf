@@ -0,0 +1,18 @@
+data "pleasantpassword_credential_attachments" "tls" {
+  credential_id = "9f0a6b3e-2f4c-4d1a-8b7e-5c3d2a1f0e9d"
+  file_name     = "tls-bundle.pem"
+}
+
+resource "kubernetes_secret" "tls" {
+  metadata {
+    name = "tls-bundle"
+  }
+
+  binary_data = {
+    "bundle.pem" = data.pleasantpassword_credential_attachments.tls.content_base64
+  }
+}
+
+output "attachment_names" {
+  value = data.pleasantpassword_credential_attachments.tls.attachments[*].file_name
+}
@@ -0,0 +1,11 @@
+ephemeral "pleasantpassword_credential" "database" {
+  credential_id = "9f0a6b3e-2f4c-4d1a-8b7e-5c3d2a1f0e9d"
+}
+
+# The credential is read on every run and never stored in the plan or the
+# state.
+provider "postgresql" {
+  host     = ephemeral.pleasantpassword_credential.database.url
+  username = ephemeral.pleasantpassword_credential.database.username
+  password = ephemeral.pleasantpassword_credential.database.password
+}
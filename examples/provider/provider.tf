@@ -1,6 +1,6 @@
 provider "pleasantpassword" {
 
-  server_url = "localhost:10001"
+  server_url = "https://localhost:10001"
   password   = "secretpassword"
   username   = "admin"
 
@@ -0,0 +1,8 @@
+# Import a credential by its ID
+terraform import pleasantpassword_credential.cred1 9f0a6b3e-2f4c-4d1a-8b7e-5c3d2a1f0e9d
+
+# Import a credential by its folder path, the last segment being the credential name
+terraform import pleasantpassword_credential.cred1 "Root/Infra/Prod/db-admin"
+
+# A slash in a folder or credential name is escaped with a backslash
+terraform import pleasantpassword_credential.cred1 'Root/Infra/CI\/CD/deploy-key'
@@ -14,6 +14,73 @@ resource "pleasantpassword_credential" "cred1" {
   password  = "example_password"
   notes     = "example notes"
   username  = "example_username1"
+  tags      = ["team-infra", "env-prod"]
 
+  custom_fields = {
+    host = "db.example.com"
+    port = "5432"
+  }
+  sensitive_custom_fields = {
+    ssh_passphrase = "example_passphrase"
+  }
 
-}
\ No newline at end of file
+
+}
+
+resource "pleasantpassword_credential" "generated" {
+  name      = "example_generated_credential"
+  folder_id = pleasantpassword_folder.create_folder.id
+  username  = "example_username2"
+
+  generate_password {
+    length             = 24
+    exclude_characters = "0O1lI"
+  }
+
+  # Changing a keeper generates a new password.
+  keepers = {
+    rotation = "2024-01"
+  }
+}
+
+# Run terraform apply on a schedule to rotate the password every 90 days.
+resource "pleasantpassword_credential" "rotated" {
+  name      = "example_rotated_credential"
+  folder_id = pleasantpassword_folder.create_folder.id
+  username  = "example_username3"
+
+  # Only a hash of the password is kept in the state.
+  store_password_in_state = false
+
+  generate_password {
+    length = 32
+  }
+
+  rotation {
+    interval = "90d"
+  }
+}
+
+# The password is read from an ephemeral source and never stored in the
+# state. Increment password_wo_version to send a new password.
+ephemeral "random_password" "service" {
+  length = 32
+}
+
+resource "pleasantpassword_credential" "write_only" {
+  name                = "example_write_only_credential"
+  folder_id           = pleasantpassword_folder.create_folder.id
+  username            = "example_username4"
+  password_wo         = ephemeral.random_password.service.result
+  password_wo_version = 1
+}
+
+# The TOTP seed of a service account protected by two-factor authentication.
+# The data source exposes the current code as otp_code.
+resource "pleasantpassword_credential" "two_factor" {
+  name       = "example_two_factor_credential"
+  folder_id  = pleasantpassword_folder.create_folder.id
+  username   = "example_username5"
+  otp_secret = "JBSWY3DPEHPK3PXP"
+  otp_issuer = "Example Console"
+}
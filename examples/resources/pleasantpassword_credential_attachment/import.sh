@@ -0,0 +1,2 @@
+# Import an attachment by the ID of its credential and its own ID
+terraform import pleasantpassword_credential_attachment.keytab 9f0a6b3e-2f4c-4d1a-8b7e-5c3d2a1f0e9d/3d1c5b7a-8e2f-4a6d-9c0b-1e2f3a4b5c6d
@@ -0,0 +1,29 @@
+data "pleasantpassword_folder_root" "get_root_folder" {
+}
+
+resource "pleasantpassword_credential" "service" {
+  name      = "example_service_credential"
+  folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+  username  = "svc-example"
+}
+
+# A text file
+resource "pleasantpassword_credential_attachment" "kubeconfig" {
+  credential_id = pleasantpassword_credential.service.id
+  file_name     = "kubeconfig"
+  content       = file("${path.module}/kubeconfig")
+}
+
+# A binary file read from disk
+resource "pleasantpassword_credential_attachment" "keytab" {
+  credential_id = pleasantpassword_credential.service.id
+  file_name     = "svc-example.keytab"
+  source        = "${path.module}/svc-example.keytab"
+}
+
+# A binary file encoded in base64
+resource "pleasantpassword_credential_attachment" "pfx" {
+  credential_id  = pleasantpassword_credential.service.id
+  file_name      = "svc-example.pfx"
+  content_base64 = filebase64("${path.module}/svc-example.pfx")
+}
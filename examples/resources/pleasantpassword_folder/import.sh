@@ -0,0 +1,8 @@
+# Import a folder by its ID
+terraform import pleasantpassword_folder.create_folder 4c2e8d1a-7b3f-4e6a-9d0c-1f2b3a4c5d6e
+
+# Import a folder by its path, starting at the root folder
+terraform import pleasantpassword_folder.create_folder "Root/Infra/Prod"
+
+# A slash in a folder name is escaped with a backslash
+terraform import pleasantpassword_folder.create_folder 'Root/Infra/CI\/CD'
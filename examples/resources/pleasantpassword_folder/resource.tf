@@ -5,4 +5,12 @@ resource "pleasantpassword_folder" "create_folder" {
   name      = "example_folder"
   parent_id = data.pleasantpassword_folder_root.get_root_folder.id
   notes     = " example notes"
-}
\ No newline at end of file
+}
+
+# Destroying the folder moves it, with its subfolders and credentials, to the
+# archive instead of deleting them permanently.
+resource "pleasantpassword_folder" "team" {
+  name          = "example_team_folder"
+  parent_id     = data.pleasantpassword_folder_root.get_root_folder.id
+  deletion_mode = "archive"
+}
@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+// responseError is an error response to a request made by callAPI.
+type responseError struct {
+	status string
+	body   []byte
+}
+
+func (e *responseError) Error() string {
+	return e.status
+}
+
+func (e *responseError) Body() []byte {
+	return e.body
+}
+
+// callAPI calls an endpoint that the generated client does not cover. The
+// request goes through the HTTP client of client, so that it is
+// authenticated, retried, throttled and logged as any other. in is sent as
+// JSON when not nil, and the response is decoded into out when not nil.
+func callAPI(ctx context.Context, client *PPSClient.APIClient, method string, path string, in interface{}, out interface{}) (*http.Response, error) {
+	cfg := client.GetConfig()
+
+	serverURL, err := cfg.ServerURLWithContext(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, serverURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	}
+	for name, value := range cfg.DefaultHeader {
+		req.Header.Set(name, value)
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	httpres, err := httpClient.Do(req)
+	if err != nil {
+		return httpres, err
+	}
+	defer func() { _ = httpres.Body.Close() }()
+
+	data, err := io.ReadAll(httpres.Body)
+	if err != nil {
+		return httpres, err
+	}
+
+	if httpres.StatusCode >= http.StatusMultipleChoices {
+		return httpres, &responseError{status: httpres.Status, body: data}
+	}
+
+	if out != nil && len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return httpres, fmt.Errorf("unable to decode the response: %w", err)
+		}
+	}
+
+	return httpres, nil
+}
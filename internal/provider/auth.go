@@ -0,0 +1,301 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+// errProviderConfigUnknown is returned by every API call made while the
+// provider configuration still contains values that are only known at apply.
+var errProviderConfigUnknown = errors.New("the provider configuration depends on values that are not known until apply, " +
+	"the Pleasant Password Server cannot be reached before then")
+
+// tokenExpiryDelta is how long before its reported expiry a token is renewed.
+const tokenExpiryDelta = time.Minute
+
+// otpHeader and otpProviderHeader carry the second factor of the password
+// grant. The server also sets them on its response when a user enrolled in
+// two-factor authentication logs in without one, otpHeader being "required".
+const (
+	otpHeader         = "X-Pleasant-OTP"
+	otpProviderHeader = "X-Pleasant-OTP-Provider"
+)
+
+// otpSettings configure the second factor sent with the password grant.
+type otpSettings struct {
+	// code is a one-time password entered by the user, it can only be used
+	// for a single login.
+	code string
+	// totpSecret is the base32 seed of an authenticator app, used to compute
+	// a fresh code for every login.
+	totpSecret string
+	// provider is the name of the two-factor provider, it is taken from the
+	// server's challenge when empty.
+	provider string
+}
+
+func (o otpSettings) configured() bool {
+	return o.code != "" || o.totpSecret != ""
+}
+
+// Code returns the one-time password to send, or an empty string when no
+// second factor is configured.
+func (o otpSettings) Code() (string, error) {
+	if o.totpSecret != "" {
+		return totpCode(o.totpSecret, time.Now(), totpDefaultDigits, totpDefaultPeriod)
+	}
+
+	return o.code, nil
+}
+
+// tokenSource hands out bearer tokens for the Pleasant Password Server API. It
+// runs the password grant on first use and again shortly before the current
+// token expires. It is shared by every resource and data source of a provider
+// instance, so all access is serialized.
+type tokenSource struct {
+	// client is used for the token endpoint only and must not itself
+	// authenticate through the tokenSource.
+	client   *PPSClient.APIClient
+	username string
+	password string
+	otp      otpSettings
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func newTokenSource(client *PPSClient.APIClient, username string, password string, otp otpSettings) *tokenSource {
+	return &tokenSource{
+		client:   client,
+		username: username,
+		password: password,
+		otp:      otp,
+	}
+}
+
+// Token returns a valid access token, authenticating if there is none yet or
+// the current one is about to expire.
+func (s *tokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expiry.IsZero() || time.Now().Before(s.expiry)) {
+		return s.token, nil
+	}
+
+	return s.authenticate(ctx)
+}
+
+// Invalidate discards token so that the next call to Token authenticates
+// again. It is a no-op if the token was already replaced by another request.
+func (s *tokenSource) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = ""
+		s.expiry = time.Time{}
+	}
+}
+
+// authenticate runs the password grant, s.mu must be held by the caller.
+func (s *tokenSource) authenticate(ctx context.Context) (string, error) {
+	// Without a known provider the second factor is only sent once the
+	// server asked for it, as its challenge names the provider to use.
+	provider := s.otp.provider
+
+	res, httpres, err := s.passwordGrant(ctx, provider)
+	if err != nil && httpres != nil && strings.EqualFold(httpres.Header.Get(otpHeader), "required") {
+		if provider == "" {
+			provider = httpres.Header.Get(otpProviderHeader)
+		}
+
+		if !s.otp.configured() {
+			return "", fmt.Errorf("authentication failed: the server requires two-factor authentication with %q, set otp or totp_secret in the provider configuration", provider)
+		}
+
+		res, httpres, err = s.passwordGrant(ctx, provider)
+	}
+	if err != nil {
+		return "", authError(httpres, err)
+	}
+
+	if res.GetAccessToken() == "" {
+		return "", errors.New("authentication failed: the server returned an empty access token")
+	}
+
+	s.token = res.GetAccessToken()
+	s.expiry = time.Time{}
+
+	if expiresIn := res.GetExpiresIn(); expiresIn > 0 {
+		lifetime := time.Duration(expiresIn) * time.Second
+		if lifetime > 2*tokenExpiryDelta {
+			lifetime -= tokenExpiryDelta
+		} else {
+			lifetime /= 2
+		}
+		s.expiry = time.Now().Add(lifetime)
+	}
+
+	return s.token, nil
+}
+
+// passwordGrant requests a token, sending the second factor when an OTP
+// provider is given.
+func (s *tokenSource) passwordGrant(ctx context.Context, provider string) (*PPSClient.Oauth2TokenOutput, *http.Response, error) {
+	req := s.client.AuthenticationAPI.PostOauthToken(ctx).GrantType("password").Username(s.username).Password(s.password)
+
+	if provider != "" && s.otp.configured() {
+		code, err := s.otp.Code()
+		if err != nil {
+			return nil, nil, err
+		}
+		req = req.XPleasantOTP(code).XPleasantOTPProvider(provider)
+	}
+
+	return req.Execute()
+}
+
+// authError turns a failed token request into an error carrying the OAuth2
+// error description returned by the server, when there is one.
+func authError(httpres *http.Response, err error) error {
+	var apierr *PPSClient.GenericOpenAPIError
+	if errors.As(err, &apierr) {
+		var body PPSClient.WithDescriptionError
+		if json.Unmarshal(apierr.Body(), &body) == nil && body.GetErrorDescription() != "" {
+			return fmt.Errorf("authentication failed with %s: %s", err.Error(), body.GetErrorDescription())
+		}
+	}
+
+	if httpres != nil {
+		return fmt.Errorf("authentication failed with %s: %w", httpres.Status, err)
+	}
+
+	return fmt.Errorf("authentication failed: %w", err)
+}
+
+// authTransport adds the bearer token to every request. When the server
+// rejects a token with 401 Unauthorized it authenticates again and retries the
+// request once.
+type authTransport struct {
+	tokens *tokenSource
+	next   http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.tokens.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(withBearerToken(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// The request body has already been consumed and cannot be sent again.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	t.tokens.Invalidate(token)
+
+	token, err = t.tokens.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	retry := withBearerToken(req, token)
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t.next.RoundTrip(retry)
+}
+
+// withBearerToken returns a copy of req carrying token in its Authorization
+// header, RoundTrippers must not modify the request they are given.
+func withBearerToken(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+token)
+
+	return clone
+}
+
+// unavailableTransport fails every request with err, it stands in for the real
+// transport while the provider cannot be configured yet.
+type unavailableTransport struct {
+	err error
+}
+
+func (t *unavailableTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// isConfigUnknown reports whether client stands in for a provider whose
+// configuration is not known yet.
+func isConfigUnknown(client *PPSClient.APIClient) bool {
+	httpClient := client.GetConfig().HTTPClient
+	if httpClient == nil {
+		return false
+	}
+
+	transport, ok := httpClient.Transport.(*unavailableTransport)
+
+	return ok && errors.Is(transport.err, errProviderConfigUnknown)
+}
+
+// skipReadWhileConfigUnknown reports whether the read of a resource must be
+// skipped as client stands in for a provider whose configuration is not known
+// yet, adding a warning when it does. The resource keeps its prior state until
+// the configuration is known.
+func skipReadWhileConfigUnknown(client *PPSClient.APIClient, diags *diag.Diagnostics) bool {
+	if !isConfigUnknown(client) {
+		return false
+	}
+
+	diags.AddWarning(
+		"Provider Configuration Unknown",
+		"The provider configuration depends on values that are not known until apply, so the Pleasant Password Server was not read during plan and resources keep their prior state.",
+	)
+
+	return true
+}
+
+// failWhileConfigUnknown reports whether client stands in for a provider whose
+// configuration is not known yet, adding an error when it does. Data sources
+// and ephemeral resources cannot return unknown values, and Terraform would
+// use anything else they return during plan as their final value.
+func failWhileConfigUnknown(client *PPSClient.APIClient, diags *diag.Diagnostics) bool {
+	if !isConfigUnknown(client) {
+		return false
+	}
+
+	diags.AddError(
+		"Provider Configuration Unknown",
+		"The provider configuration depends on values that are not known until apply, and this version of Terraform cannot defer reading data sources and ephemeral resources until then. "+
+			"Configure the provider with known values, or apply the resources it depends on first, e.g. with -target.",
+	)
+
+	return true
+}
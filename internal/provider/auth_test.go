@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+// newTestAuthServer serves the token endpoint, handing out token-1, token-2,
+// ... and the root folder endpoint, which only accepts the token returned by
+// valid.
+func newTestAuthServer(t *testing.T, logins *int32, valid func() string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/OAuth2/Token", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(logins, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":3600}`, n)
+	})
+	mux.HandleFunc("/api/v6/rest/folders/root", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `"00000000-0000-0000-0000-000000000001"`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestAuthClient(t *testing.T, server *httptest.Server) (*PPSClient.APIClient, *tokenSource) {
+	t.Helper()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := PPSClient.NewConfiguration()
+	cfg.Host = serverURL.Host
+	cfg.Scheme = serverURL.Scheme
+
+	authcfg := *cfg
+	authcfg.HTTPClient = server.Client()
+	tokens := newTokenSource(PPSClient.NewAPIClient(&authcfg), "user", "password", otpSettings{})
+
+	cfg.HTTPClient = &http.Client{Transport: &authTransport{tokens: tokens, next: server.Client().Transport}}
+
+	return PPSClient.NewAPIClient(cfg), tokens
+}
+
+func TestAuthTransportReauthenticatesOnUnauthorized(t *testing.T) {
+	var logins int32
+	server := newTestAuthServer(t, &logins, func() string { return "token-2" })
+	client, _ := newTestAuthClient(t, server)
+
+	_, httpres, err := client.DefaultAPI.GetV6FoldersRoot(context.Background()).Execute()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if httpres.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", httpres.StatusCode)
+	}
+	if n := atomic.LoadInt32(&logins); n != 2 {
+		t.Fatalf("expected 2 logins, got %d", n)
+	}
+}
+
+func TestTokenSourceRenewsExpiredToken(t *testing.T) {
+	var logins int32
+	server := newTestAuthServer(t, &logins, func() string { return fmt.Sprintf("token-%d", atomic.LoadInt32(&logins)) })
+	client, tokens := newTestAuthClient(t, server)
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := client.DefaultAPI.GetV6FoldersRoot(context.Background()).Execute(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if n := atomic.LoadInt32(&logins); n != 1 {
+		t.Fatalf("expected the token to be reused, got %d logins", n)
+	}
+
+	tokens.mu.Lock()
+	tokens.expiry = time.Now().Add(-time.Second)
+	tokens.mu.Unlock()
+
+	if _, _, err := client.DefaultAPI.GetV6FoldersRoot(context.Background()).Execute(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := atomic.LoadInt32(&logins); n != 2 {
+		t.Fatalf("expected the expired token to be renewed, got %d logins", n)
+	}
+}
+
+func TestTokenSourceAnswersOTPChallenge(t *testing.T) {
+	secret := "JBSWY3DPEHPK3PXP"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/OAuth2/Token", func(w http.ResponseWriter, r *http.Request) {
+		expected, err := totpCode(secret, time.Now(), totpDefaultDigits, totpDefaultPeriod)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if r.Header.Get(otpHeader) != expected || r.Header.Get(otpProviderHeader) != "authenticator-app" {
+			w.Header().Set(otpHeader, "required")
+			w.Header().Set(otpProviderHeader, "authenticator-app")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant","error_description":"A one-time password is required"}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"token-otp","token_type":"bearer","expires_in":3600}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := PPSClient.NewConfiguration()
+	cfg.Host = serverURL.Host
+	cfg.Scheme = serverURL.Scheme
+	cfg.HTTPClient = server.Client()
+
+	token, err := newTokenSource(PPSClient.NewAPIClient(cfg), "user", "password", otpSettings{totpSecret: secret}).Token(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if token != "token-otp" {
+		t.Fatalf("expected token-otp, got %s", token)
+	}
+
+	_, err = newTokenSource(PPSClient.NewAPIClient(cfg), "user", "password", otpSettings{}).Token(context.Background())
+	if err == nil {
+		t.Fatal("expected an error without a second factor")
+	}
+}
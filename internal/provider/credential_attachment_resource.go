@@ -0,0 +1,425 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &CredentialAttachmentResource{}
+var _ resource.ResourceWithImportState = &CredentialAttachmentResource{}
+var _ resource.ResourceWithValidateConfig = &CredentialAttachmentResource{}
+var _ resource.ResourceWithModifyPlan = &CredentialAttachmentResource{}
+
+func NewCredentialAttachmentResource() resource.Resource {
+	return &CredentialAttachmentResource{}
+}
+
+type CredentialAttachmentResource struct {
+	client *PPSClient.APIClient
+	ctx    *context.Context
+}
+
+type CredentialAttachmentResourceModel struct {
+	Id            types.String `tfsdk:"id"`
+	CredentialID  types.String `tfsdk:"credential_id"`
+	AttachmentID  types.String `tfsdk:"attachment_id"`
+	FileName      types.String `tfsdk:"file_name"`
+	Content       types.String `tfsdk:"content"`
+	ContentBase64 types.String `tfsdk:"content_base64"`
+	Source        types.String `tfsdk:"source"`
+	ContentSHA256 types.String `tfsdk:"content_sha256"`
+	FileSize      types.Int64  `tfsdk:"file_size"`
+}
+
+func (r *CredentialAttachmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_credential_attachment"
+}
+
+func (r *CredentialAttachmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The `credential_attachment` resource attaches a file to a credential in Pleasant Password Server. The attachment is replaced when its content changes, in the configuration or on the server.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The identifier of the attachment in the form `<credential_id>/<attachment_id>`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"credential_id": schema.StringAttribute{
+				MarkdownDescription: "The identifier of the credential the file is attached to.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"attachment_id": schema.StringAttribute{
+				MarkdownDescription: "The identifier of the attachment.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"file_name": schema.StringAttribute{
+				MarkdownDescription: "The name of the file.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "The content of the file, as text. Exactly one of `content`, `content_base64` and `source` must be set.",
+				Sensitive:           true,
+				Optional:            true,
+			},
+			"content_base64": schema.StringAttribute{
+				MarkdownDescription: "The content of the file, encoded in base64, for binary files such as keytabs or PFX bundles.",
+				Sensitive:           true,
+				Optional:            true,
+			},
+			"source": schema.StringAttribute{
+				MarkdownDescription: "The path of a local file to read the content from.",
+				Optional:            true,
+			},
+			"content_sha256": schema.StringAttribute{
+				MarkdownDescription: "The SHA-256 hash of the content of the file, in hexadecimal. A content changed on the server is detected through it and uploaded again.",
+				Computed:            true,
+			},
+			"file_size": schema.Int64Attribute{
+				MarkdownDescription: "The size of the file in bytes.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *CredentialAttachmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerclient, ok := req.ProviderData.(ProviderClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *PPSClient.APIClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = &providerclient.Client
+	r.ctx = &providerclient.Ctx
+}
+
+func (r *CredentialAttachmentResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data CredentialAttachmentResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sources := map[string]attr.Value{
+		"content":        data.Content,
+		"content_base64": data.ContentBase64,
+		"source":         data.Source,
+	}
+
+	set := 0
+	for _, value := range sources {
+		if value.IsUnknown() {
+			return
+		}
+		if !value.IsNull() {
+			set++
+		}
+	}
+
+	if set != 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("content"),
+			"Invalid Attachment Content",
+			"Exactly one of content, content_base64 and source must be set.",
+		)
+		return
+	}
+
+	if !data.ContentBase64.IsNull() {
+		if _, err := base64.StdEncoding.DecodeString(data.ContentBase64.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("content_base64"), "Invalid Base64 Content", err.Error()+".")
+		}
+	}
+}
+
+// attachmentContent returns the content of the file configured in data,
+// known being false while the attribute it comes from is unknown.
+func attachmentContent(data CredentialAttachmentResourceModel) (content []byte, known bool, err error) {
+	switch {
+	case data.Content.IsUnknown() || data.ContentBase64.IsUnknown() || data.Source.IsUnknown():
+		return nil, false, nil
+	case !data.Content.IsNull():
+		return []byte(data.Content.ValueString()), true, nil
+	case !data.ContentBase64.IsNull():
+		content, err = base64.StdEncoding.DecodeString(data.ContentBase64.ValueString())
+		return content, true, err
+	case !data.Source.IsNull():
+		content, err = os.ReadFile(data.Source.ValueString())
+		return content, true, err
+	}
+
+	return nil, true, fmt.Errorf("exactly one of content, content_base64 and source must be set")
+}
+
+// ModifyPlan plans the hash and size of the configured content, and replaces
+// the attachment when they differ from the content on the server.
+func (r *CredentialAttachmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var data CredentialAttachmentResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, known, err := attachmentContent(data)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to read the content of the attachment", err.Error()+".")
+		return
+	}
+
+	if !known {
+		data.ContentSHA256 = types.StringUnknown()
+		data.FileSize = types.Int64Unknown()
+	} else {
+		data.ContentSHA256 = types.StringValue(contentHash(content))
+		data.FileSize = types.Int64Value(int64(len(content)))
+	}
+
+	if !req.State.Raw.IsNull() {
+		var state CredentialAttachmentResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// The API has no way to change the content of an attachment, a new
+		// content is uploaded as a new attachment.
+		if !data.ContentSHA256.Equal(state.ContentSHA256) {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("content_sha256"))
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &data)...)
+}
+
+// attachmentInput is the body of the request adding an attachment to a
+// credential, the content being encoded in base64 by encoding/json.
+type attachmentInput struct {
+	CredentialObjectId string
+	FileName           string
+	FileData           []byte
+}
+
+// attachmentsPath returns the path of the attachments of a credential. The
+// generated client has no attachment endpoints, they are called through
+// callAPI.
+func attachmentsPath(credentialID string) string {
+	return "/api/v6/rest/entries/" + url.PathEscape(credentialID) + "/attachments"
+}
+
+// attachmentResourceID returns the ID of the credential_attachment resource.
+func attachmentResourceID(credentialID string, attachmentID string) string {
+	return credentialID + "/" + attachmentID
+}
+
+// splitAttachmentResourceID returns the credential and attachment IDs of the
+// ID of the credential_attachment resource.
+func splitAttachmentResourceID(id string) (credentialID string, attachmentID string, ok bool) {
+	credentialID, attachmentID, ok = strings.Cut(id, "/")
+	if !ok || credentialID == "" || attachmentID == "" || strings.Contains(attachmentID, "/") {
+		return "", "", false
+	}
+
+	return credentialID, attachmentID, true
+}
+
+func (r *CredentialAttachmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data CredentialAttachmentResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, _, err := attachmentContent(data)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to read the content of the attachment", err.Error()+".")
+		return
+	}
+
+	credential_id := data.CredentialID.ValueString()
+	param := attachmentInput{
+		CredentialObjectId: credential_id,
+		FileName:           data.FileName.ValueString(),
+		FileData:           content,
+	}
+
+	var res string
+	httpres, err := callAPI(*r.ctx, r.client, http.MethodPost, attachmentsPath(credential_id), param, &res)
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "attach the file", permission: "Modify", object: fmt.Sprintf("credential %q", credential_id)}, httpres, err, 200, 201) {
+		return
+	}
+
+	sanityresult, err := strconv.Unquote(res)
+	if err != nil {
+		sanityresult = res
+	}
+
+	data.AttachmentID = types.StringValue(sanityresult)
+	data.Id = types.StringValue(attachmentResourceID(credential_id, sanityresult))
+	data.ContentSHA256 = types.StringValue(contentHash(content))
+	data.FileSize = types.Int64Value(int64(len(content)))
+
+	tflog.Trace(ctx, "Attached file", map[string]interface{}{"id": data.Id.ValueString()})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CredentialAttachmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if skipReadWhileConfigUnknown(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var data CredentialAttachmentResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	credential_id, attachment_id, ok := splitAttachmentResourceID(data.Id.ValueString())
+	if !ok {
+		resp.Diagnostics.AddError("Invalid Attachment Identifier", fmt.Sprintf("Expected <credential_id>/<attachment_id>, got: %q.", data.Id.ValueString()))
+		return
+	}
+
+	attachments, httpres, err := readAttachments(*r.ctx, r.client, credential_id)
+	if isNotFound(httpres) {
+		tflog.Warn(ctx, "Credential not found, removing its attachment from the state", map[string]interface{}{"id": data.Id.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "read the attachments of the credential", permission: "View", object: fmt.Sprintf("credential %q", credential_id)}, httpres, err, 200) {
+		return
+	}
+
+	var attachment *apiAttachment
+	for i := range attachments {
+		if attachments[i].AttachmentId == attachment_id {
+			attachment = &attachments[i]
+			break
+		}
+	}
+	if attachment == nil {
+		tflog.Warn(ctx, "Attachment not found, removing it from the state", map[string]interface{}{"id": data.Id.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// The content attributes keep their configured values, a content
+	// changed on the server shows as a different content_sha256.
+	data.CredentialID = types.StringValue(credential_id)
+	data.AttachmentID = types.StringValue(attachment_id)
+	data.FileName = types.StringValue(attachment.FileName)
+	data.ContentSHA256 = types.StringValue(contentHash(attachment.FileData))
+	data.FileSize = types.Int64Value(int64(len(attachment.FileData)))
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update only stores a content configured differently, e.g. moved from
+// content to source, as ModifyPlan replaces the attachment when the content
+// itself changes.
+func (r *CredentialAttachmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data CredentialAttachmentResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CredentialAttachmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data CredentialAttachmentResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	credential_id := data.CredentialID.ValueString()
+	httpres, err := callAPI(*r.ctx, r.client, http.MethodDelete, attachmentsPath(credential_id)+"/"+url.PathEscape(data.AttachmentID.ValueString()), nil, nil)
+
+	// The attachment or its credential was already deleted outside of
+	// Terraform.
+	if isNotFound(httpres) {
+		return
+	}
+	checkAPIResponse(&resp.Diagnostics, apiOperation{action: "delete the attachment", permission: "Modify", object: fmt.Sprintf("credential %q", credential_id)}, httpres, err, 200, 204)
+}
+
+func (r *CredentialAttachmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if _, _, ok := splitAttachmentResourceID(req.ID); !ok {
+		resp.Diagnostics.AddError(
+			"Invalid Import Identifier",
+			fmt.Sprintf("Expected <credential_id>/<attachment_id>, got: %q", req.ID),
+		)
+		return
+	}
+
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
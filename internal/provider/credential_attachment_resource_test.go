@@ -0,0 +1,246 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccCredentialAttachmentResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccCredentialAttachmentResourceConfig("first"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pleasantpassword_credential_attachment.attachment_test", "file_name", "acctest.txt"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential_attachment.attachment_test", "file_size", "5"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential_attachment.attachment_test", "content_sha256", contentHash([]byte("first"))),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "pleasantpassword_credential_attachment.attachment_test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"content"},
+			},
+			// A new content replaces the attachment
+			{
+				Config: testAccCredentialAttachmentResourceConfig("second"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pleasantpassword_credential_attachment.attachment_test", "file_size", "6"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential_attachment.attachment_test", "content_sha256", contentHash([]byte("second"))),
+					resource.TestCheckResourceAttrSet("pleasantpassword_credential_attachment.attachment_test", "attachment_id"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccCredentialAttachmentResourceConfig(content string) string {
+	return `
+data "pleasantpassword_folder_root" "get_root_folder" {
+}
+
+resource "pleasantpassword_credential" "attachment_test" {
+	name = "acctest_attachment"
+	folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+}
+
+resource "pleasantpassword_credential_attachment" "attachment_test" {
+	credential_id = pleasantpassword_credential.attachment_test.id
+	file_name = "acctest.txt"
+	content = "` + content + `"
+}
+`
+}
+
+func TestCredentialAttachmentResourceRead(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v6/rest/entries/42":
+			_, _ = w.Write([]byte(`{"Id":"42","Attachments":[{"CredentialObjectId":"42","AttachmentId":"a1","FileName":"bundle.pem","FileData":"` + base64.StdEncoding.EncodeToString([]byte("bundle1")) + `"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"Message":"Not found."}`))
+		}
+	})
+
+	resp := testResourceRead(t, NewCredentialAttachmentResource(), client, "42/a1")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var data CredentialAttachmentResourceModel
+	resp.Diagnostics.Append(resp.State.Get(context.Background(), &data)...)
+	if data.CredentialID.ValueString() != "42" || data.AttachmentID.ValueString() != "a1" || data.FileName.ValueString() != "bundle.pem" {
+		t.Errorf("unexpected attachment %+v", data)
+	}
+	if data.ContentSHA256.ValueString() != contentHash([]byte("bundle1")) || data.FileSize.ValueInt64() != 7 {
+		t.Errorf("unexpected content hash %s or size %s", data.ContentSHA256, data.FileSize)
+	}
+
+	// A missing attachment or credential is removed from the state.
+	for _, id := range []string{"42/a2", "43/a1"} {
+		resp = testResourceRead(t, NewCredentialAttachmentResource(), client, id)
+		if resp.Diagnostics.HasError() || !resp.State.Raw.IsNull() {
+			t.Errorf("%s: expected the attachment to be removed from the state, got: %v", id, resp.Diagnostics)
+		}
+	}
+}
+
+func TestCredentialAttachmentResourceModifyPlanReplacesChangedContent(t *testing.T) {
+	ctx := context.Background()
+	r := NewCredentialAttachmentResource().(*CredentialAttachmentResource)
+
+	values := map[string]tftypes.Value{
+		"id":             tftypes.NewValue(tftypes.String, "42/a1"),
+		"credential_id":  tftypes.NewValue(tftypes.String, "42"),
+		"attachment_id":  tftypes.NewValue(tftypes.String, "a1"),
+		"file_name":      tftypes.NewValue(tftypes.String, "bundle.pem"),
+		"content":        tftypes.NewValue(tftypes.String, "bundle1"),
+		"content_sha256": tftypes.NewValue(tftypes.String, contentHash([]byte("bundle1"))),
+		"file_size":      tftypes.NewValue(tftypes.Number, 7),
+	}
+	state := testResourceConfig(t, r, values)
+
+	modifyPlan := func(content string) *fwresource.ModifyPlanResponse {
+		values["content"] = tftypes.NewValue(tftypes.String, content)
+		config := testResourceConfig(t, r, values)
+		plan := tfsdk.Plan{Schema: config.Schema, Raw: config.Raw}
+		resp := &fwresource.ModifyPlanResponse{Plan: plan}
+		r.ModifyPlan(ctx, fwresource.ModifyPlanRequest{Config: config, Plan: plan, State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+		return resp
+	}
+
+	if resp := modifyPlan("bundle1"); len(resp.RequiresReplace) != 0 {
+		t.Errorf("expected an unchanged content not to replace the attachment, got %v", resp.RequiresReplace)
+	}
+
+	resp := modifyPlan("bundle2")
+	if len(resp.RequiresReplace) != 1 || !resp.RequiresReplace[0].Equal(path.Root("content_sha256")) {
+		t.Errorf("expected a changed content to replace the attachment, got %v", resp.RequiresReplace)
+	}
+	var planned CredentialAttachmentResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &planned)...)
+	if planned.ContentSHA256.ValueString() != contentHash([]byte("bundle2")) {
+		t.Errorf("expected the hash of the new content to be planned, got %s", planned.ContentSHA256)
+	}
+}
+
+func TestCredentialAttachmentResourceCreateAndDelete(t *testing.T) {
+	var requests []string
+	var body attachmentInput
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			_, _ = w.Write([]byte(`"a1"`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	source := filepath.Join(t.TempDir(), "krb5.keytab")
+	if err := os.WriteFile(source, []byte{0x05, 0x02, 0x00}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	r := NewCredentialAttachmentResource().(*CredentialAttachmentResource)
+	r.Configure(ctx, fwresource.ConfigureRequest{ProviderData: ProviderClient{Client: *client, Ctx: ctx}}, &fwresource.ConfigureResponse{})
+
+	config := testResourceConfig(t, r, map[string]tftypes.Value{
+		"id":             tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"credential_id":  tftypes.NewValue(tftypes.String, "42"),
+		"attachment_id":  tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"file_name":      tftypes.NewValue(tftypes.String, "krb5.keytab"),
+		"source":         tftypes.NewValue(tftypes.String, source),
+		"content_sha256": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"file_size":      tftypes.NewValue(tftypes.Number, tftypes.UnknownValue),
+	})
+	createResp := &fwresource.CreateResponse{State: tfsdk.State{Schema: config.Schema, Raw: config.Raw}}
+	r.Create(ctx, fwresource.CreateRequest{Config: config, Plan: tfsdk.Plan{Schema: config.Schema, Raw: config.Raw}}, createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", createResp.Diagnostics)
+	}
+
+	if body.CredentialObjectId != "42" || body.FileName != "krb5.keytab" || string(body.FileData) != "\x05\x02\x00" {
+		t.Errorf("unexpected attachment sent %+v", body)
+	}
+
+	var data CredentialAttachmentResourceModel
+	createResp.Diagnostics.Append(createResp.State.Get(ctx, &data)...)
+	if data.Id.ValueString() != "42/a1" || data.FileSize.ValueInt64() != 3 {
+		t.Errorf("unexpected state %+v", data)
+	}
+
+	deleteResp := &fwresource.DeleteResponse{}
+	r.Delete(ctx, fwresource.DeleteRequest{State: createResp.State}, deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", deleteResp.Diagnostics)
+	}
+
+	if len(requests) != 2 || requests[0] != "POST /api/v6/rest/entries/42/attachments" || requests[1] != "DELETE /api/v6/rest/entries/42/attachments/a1" {
+		t.Errorf("unexpected requests: %v", requests)
+	}
+}
+
+func TestCredentialAttachmentResourceValidateConfig(t *testing.T) {
+	r := NewCredentialAttachmentResource().(*CredentialAttachmentResource)
+
+	for name, c := range map[string]struct {
+		values map[string]tftypes.Value
+		valid  bool
+	}{
+		"content":        {map[string]tftypes.Value{"content": tftypes.NewValue(tftypes.String, "text")}, true},
+		"base64":         {map[string]tftypes.Value{"content_base64": tftypes.NewValue(tftypes.String, "dGV4dA==")}, true},
+		"none":           {map[string]tftypes.Value{}, false},
+		"invalid base64": {map[string]tftypes.Value{"content_base64": tftypes.NewValue(tftypes.String, "not base64!")}, false},
+		"two": {map[string]tftypes.Value{
+			"content": tftypes.NewValue(tftypes.String, "text"),
+			"source":  tftypes.NewValue(tftypes.String, "file.txt"),
+		}, false},
+	} {
+		resp := &fwresource.ValidateConfigResponse{}
+		r.ValidateConfig(context.Background(), fwresource.ValidateConfigRequest{Config: testResourceConfig(t, r, c.values)}, resp)
+		if resp.Diagnostics.HasError() == c.valid {
+			t.Errorf("%s: expected valid to be %t, got: %v", name, c.valid, resp.Diagnostics)
+		}
+	}
+}
+
+func TestSplitAttachmentResourceID(t *testing.T) {
+	credentialID, attachmentID, ok := splitAttachmentResourceID(attachmentResourceID("42", "a1"))
+	if !ok || credentialID != "42" || attachmentID != "a1" {
+		t.Errorf("unexpected IDs %q, %q", credentialID, attachmentID)
+	}
+
+	for _, id := range []string{"42", "42/", "/a1", "42/a1/b"} {
+		if _, _, ok := splitAttachmentResourceID(id); ok {
+			t.Errorf("expected %q to be rejected", id)
+		}
+	}
+}
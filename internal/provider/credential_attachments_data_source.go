@@ -0,0 +1,199 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	PPSClient "github.com/theochita/go-pleasant-password"
+	"github.com/theochita/terraform-provider-pleasantpassword/internal/provider/models"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &CredentialAttachmentsDataSource{}
+
+func NewCredentialAttachmentsDataSource() datasource.DataSource {
+	return &CredentialAttachmentsDataSource{}
+}
+
+type CredentialAttachmentsDataSource struct {
+	client *PPSClient.APIClient
+	ctx    *context.Context
+}
+
+type CredentialAttachmentsDataSourceModel struct {
+	CredentialID  types.String        `tfsdk:"credential_id"`
+	FileName      types.String        `tfsdk:"file_name"`
+	ContentBase64 types.String        `tfsdk:"content_base64"`
+	Attachments   []models.Attachment `tfsdk:"attachments"`
+}
+
+func (d CredentialAttachmentsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_credential_attachments"
+}
+
+func (d *CredentialAttachmentsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The `credential_attachments` data source lists the files attached to a credential, and can read the content of one of them.",
+
+		Attributes: map[string]schema.Attribute{
+			"credential_id": schema.StringAttribute{
+				MarkdownDescription: "The identifier of the credential",
+				Required:            true,
+			},
+			"file_name": schema.StringAttribute{
+				MarkdownDescription: "The name of the attachment to read into `content_base64`",
+				Optional:            true,
+			},
+			"content_base64": schema.StringAttribute{
+				MarkdownDescription: "The content of the attachment named `file_name`, encoded in base64",
+				Sensitive:           true,
+				Computed:            true,
+			},
+			"attachments": schema.ListNestedAttribute{
+				MarkdownDescription: "The attachments of the credential",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "The identifier of the attachment",
+							Computed:            true,
+						},
+						"file_name": schema.StringAttribute{
+							MarkdownDescription: "The name of the file",
+							Computed:            true,
+						},
+						"file_size": schema.Int64Attribute{
+							MarkdownDescription: "The size of the file in bytes",
+							Computed:            true,
+						},
+						"content_sha256": schema.StringAttribute{
+							MarkdownDescription: "The SHA-256 hash of the content of the file, in hexadecimal",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *CredentialAttachmentsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerclient, ok := req.ProviderData.(ProviderClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *PPSClient.APIClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = &providerclient.Client
+	d.ctx = &providerclient.Ctx
+}
+
+func (d *CredentialAttachmentsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if failWhileConfigUnknown(d.client, &resp.Diagnostics) {
+		return
+	}
+
+	var data CredentialAttachmentsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	credential_id := data.CredentialID.ValueString()
+
+	// The attachments are returned with their content as part of the
+	// credential.
+	attachments, httpres, err := readAttachments(*d.ctx, d.client, credential_id)
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "read the credential", permission: "View", object: fmt.Sprintf("credential %q", credential_id)}, httpres, err, 200) {
+		return
+	}
+
+	data.Attachments = []models.Attachment{}
+	data.ContentBase64 = types.StringNull()
+	found := false
+
+	for _, attachment := range attachments {
+		selected := !data.FileName.IsNull() && attachment.FileName == data.FileName.ValueString()
+		if selected && found {
+			resp.Diagnostics.AddAttributeError(path.Root("file_name"), "Ambiguous Attachment Name", fmt.Sprintf("Credential %q has several attachments named %q.", credential_id, attachment.FileName))
+			return
+		}
+
+		data.Attachments = append(data.Attachments, models.Attachment{
+			Id:            types.StringValue(attachment.AttachmentId),
+			FileName:      types.StringValue(attachment.FileName),
+			FileSize:      types.Int64Value(int64(len(attachment.FileData))),
+			ContentSHA256: types.StringValue(contentHash(attachment.FileData)),
+		})
+
+		if selected {
+			found = true
+			data.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(attachment.FileData))
+		}
+	}
+
+	if !data.FileName.IsNull() && !found {
+		resp.Diagnostics.AddAttributeError(path.Root("file_name"), "Attachment Not Found", fmt.Sprintf("Credential %q has no attachment named %q.", credential_id, data.FileName.ValueString()))
+		return
+	}
+
+	tflog.Trace(ctx, "Read attachments", map[string]interface{}{"credential_id": credential_id, "count": len(data.Attachments)})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// apiAttachment is an attachment of a credential as returned by the API. The
+// generated V6AttachmentResult leaves out AttachmentId, so the credential is
+// decoded through callAPI. The content is decoded from base64 by
+// encoding/json.
+type apiAttachment struct {
+	AttachmentId string
+	FileName     string
+	FileData     []byte
+}
+
+// readAttachments returns the attachments of a credential with their content.
+func readAttachments(ctx context.Context, client *PPSClient.APIClient, credentialID string) ([]apiAttachment, *http.Response, error) {
+	var res struct {
+		Attachments []apiAttachment
+	}
+	httpres, err := callAPI(ctx, client, http.MethodGet, "/api/v6/rest/entries/"+url.PathEscape(credentialID), nil, &res)
+
+	return res.Attachments, httpres, err
+}
+
+// contentHash returns the SHA-256 hash of the content of an attachment, in
+// hexadecimal.
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
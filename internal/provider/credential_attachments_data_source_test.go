@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccCredentialAttachmentsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: testAccCredentialAttachmentsDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pleasantpassword_credential_attachments.get_attachments_test", "attachments.#", "0"),
+					resource.TestCheckNoResourceAttr("data.pleasantpassword_credential_attachments.get_attachments_test", "content_base64"),
+				),
+			},
+		},
+	})
+}
+
+func TestCredentialAttachmentsDataSourceRead(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v6/rest/entries/42":
+			_, _ = w.Write([]byte(`{"Id":"42","Attachments":[` +
+				`{"CredentialObjectId":"42","AttachmentId":"a1","FileName":"bundle.pem","FileSize":7,"FileData":"` + base64.StdEncoding.EncodeToString([]byte("bundle1")) + `"},` +
+				`{"CredentialObjectId":"42","AttachmentId":"a2","FileName":"readme.txt","FileSize":7,"FileData":"` + base64.StdEncoding.EncodeToString([]byte("readme1")) + `"}]}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	resp := testDataSourceRead(t, NewCredentialAttachmentsDataSource(), client, map[string]tftypes.Value{
+		"credential_id": tftypes.NewValue(tftypes.String, "42"),
+		"file_name":     tftypes.NewValue(tftypes.String, "bundle.pem"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var data CredentialAttachmentsDataSourceModel
+	resp.Diagnostics.Append(resp.State.Get(context.Background(), &data)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	if data.ContentBase64.ValueString() != base64.StdEncoding.EncodeToString([]byte("bundle1")) {
+		t.Errorf("unexpected content %s", data.ContentBase64)
+	}
+	if len(data.Attachments) != 2 || data.Attachments[0].Id.ValueString() != "a1" || data.Attachments[0].ContentSHA256.ValueString() != contentHash([]byte("bundle1")) {
+		t.Fatalf("unexpected attachments %+v", data.Attachments)
+	}
+	// The hash of every attachment is known, not only the one of file_name.
+	if data.Attachments[1].ContentSHA256.ValueString() != contentHash([]byte("readme1")) || data.Attachments[1].FileSize.ValueInt64() != 7 {
+		t.Errorf("unexpected attachment %+v", data.Attachments[1])
+	}
+}
+
+const testAccCredentialAttachmentsDataSourceConfig = `
+data "pleasantpassword_folder_root" "root_folder_id_test" {
+}
+
+resource "pleasantpassword_credential" "cred1_test" {
+	name = "acctest_credential_attachments"
+	folder_id = data.pleasantpassword_folder_root.root_folder_id_test.id
+}
+
+data "pleasantpassword_credential_attachments" "get_attachments_test" {
+	credential_id = pleasantpassword_credential.cred1_test.id
+}
+`
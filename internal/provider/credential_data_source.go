@@ -10,6 +10,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	PPSClient "github.com/theochita/go-pleasant-password"
@@ -29,18 +30,23 @@ type CredentialDataSource struct {
 }
 
 type CredentialDataSourceModel struct {
-	Id           types.String `tfsdk:"id"`
-	CredentialID types.String `tfsdk:"credential_id"`
-	Tags         []models.Tag `tfsdk:"tags"`
-	Name         types.String `tfsdk:"name"`
-	Username     types.String `tfsdk:"username"`
-	Password     types.String `tfsdk:"password"`
-	Url          types.String `tfsdk:"url"`
-	Notes        types.String `tfsdk:"notes"`
-	FolderId     types.String `tfsdk:"folder_id"`
-	Created      types.String `tfsdk:"created"`
-	Modified     types.String `tfsdk:"modified"`
-	Expires      types.String `tfsdk:"expires"`
+	Id                      types.String `tfsdk:"id"`
+	CredentialID            types.String `tfsdk:"credential_id"`
+	Tags                    []models.Tag `tfsdk:"tags"`
+	Name                    types.String `tfsdk:"name"`
+	Username                types.String `tfsdk:"username"`
+	Password                types.String `tfsdk:"password"`
+	Url                     types.String `tfsdk:"url"`
+	Notes                   types.String `tfsdk:"notes"`
+	FolderId                types.String `tfsdk:"folder_id"`
+	Created                 types.String `tfsdk:"created"`
+	Modified                types.String `tfsdk:"modified"`
+	Expires                 types.String `tfsdk:"expires"`
+	PlainCustomFieldKeys    types.Set    `tfsdk:"plain_custom_field_keys"`
+	CustomFields            types.Map    `tfsdk:"custom_fields"`
+	SensitiveCustomFields   types.Map    `tfsdk:"sensitive_custom_fields"`
+	CustomApplicationFields types.Map    `tfsdk:"custom_application_fields"`
+	OTPCode                 types.String `tfsdk:"otp_code"`
 }
 
 func (d CredentialDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -71,6 +77,7 @@ func (d *CredentialDataSource) Schema(ctx context.Context, req datasource.Schema
 			},
 			"password": schema.StringAttribute{
 				MarkdownDescription: "The password of the credential",
+				Sensitive:           true,
 				Computed:            true,
 			},
 			"url": schema.StringAttribute{
@@ -97,6 +104,32 @@ func (d *CredentialDataSource) Schema(ctx context.Context, req datasource.Schema
 				MarkdownDescription: "The expiration date of the credential",
 				Computed:            true,
 			},
+			"plain_custom_field_keys": schema.SetAttribute{
+				MarkdownDescription: "The keys of the custom fields whose values are not secret, such as a host or a port, to read into `custom_fields` instead of `sensitive_custom_fields`",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"custom_fields": schema.MapAttribute{
+				MarkdownDescription: "The custom user fields of the credential listed in `plain_custom_field_keys`",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"sensitive_custom_fields": schema.MapAttribute{
+				MarkdownDescription: "The custom user fields of the credential, except the ones listed in `plain_custom_field_keys`",
+				ElementType:         types.StringType,
+				Sensitive:           true,
+				Computed:            true,
+			},
+			"custom_application_fields": schema.MapAttribute{
+				MarkdownDescription: "The custom application fields of the credential",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"otp_code": schema.StringAttribute{
+				MarkdownDescription: "The current TOTP code of the credential, computed from the TOTP properties of the credential, or null when it has none or the user may not view it",
+				Sensitive:           true,
+				Computed:            true,
+			},
 
 			"tags": schema.ListNestedAttribute{
 				Computed: true,
@@ -148,6 +181,10 @@ func (d *CredentialDataSource) fetchTags(res []PPSClient.V6TagResult) []models.T
 }
 
 func (d *CredentialDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if failWhileConfigUnknown(d.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data CredentialDataSourceModel
 
 	// Read Terraform configuration data into the model
@@ -162,12 +199,7 @@ func (d *CredentialDataSource) Read(ctx context.Context, req datasource.ReadRequ
 	client := d.client
 
 	res, httpres, err := client.DefaultAPI.GetV6CredentialsByID(*d.ctx, credential_id).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
-		return
-	}
-	if httpres.StatusCode != 200 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "read the credential", permission: "View", object: fmt.Sprintf("credential %q", credential_id)}, httpres, err, 200) {
 		return
 	}
 
@@ -181,18 +213,32 @@ func (d *CredentialDataSource) Read(ctx context.Context, req datasource.ReadRequ
 	data.Url = types.StringValue(res.GetUrl())
 	data.Notes = types.StringValue(res.GetNotes())
 	data.FolderId = types.StringValue(res.GetGroupId())
-	data.Created = types.StringValue("Not implemented")
-	data.Modified = types.StringValue("Not implemented")
-	data.Expires = types.StringValue(res.GetExpires())
+	data.Created = timestampValue(res.Created)
+	data.Modified = timestampValue(res.Modified)
+	data.Expires = expiresValue(res.Expires, types.StringNull())
 	data.Tags = d.fetchTags(res.Tags)
 
-	pwdres, httpres, err := client.DefaultAPI.GetV6CredentialPasswordByID(*d.ctx, credential_id).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
+	data.OTPCode = readOTPCode(res, path.Root("otp_code"), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plainKeys []string
+	resp.Diagnostics.Append(data.PlainCustomFieldKeys.ElementsAs(ctx, &plainKeys, false)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	if httpres.StatusCode != 200 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	isPlain := map[string]bool{}
+	for _, key := range plainKeys {
+		isPlain[key] = true
+	}
+	// As in the credential resource, the fields that are not listed are
+	// sensitive.
+	data.CustomFields, data.SensitiveCustomFields = splitCustomFields(res.CustomUserFields, unlistedKeys(res.CustomUserFields, isPlain))
+	data.CustomApplicationFields = customFieldsValue(res.CustomApplicationFields)
+
+	pwdres, httpres, err := client.DefaultAPI.GetV6CredentialPasswordByID(*d.ctx, credential_id).Execute()
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "read the password of the credential", permission: "View", object: fmt.Sprintf("credential %q", credential_id)}, httpres, err, 200) {
 		return
 	}
 
@@ -202,9 +248,7 @@ func (d *CredentialDataSource) Read(ctx context.Context, req datasource.ReadRequ
 	}
 	data.Password = types.StringValue(sanitypassword)
 
-	// Write logs using the tflog package
-	// Documentation: https://terraform.io/plugin/log
-	tflog.Trace(ctx, "read a data source")
+	tflog.Trace(ctx, "Read credential", map[string]interface{}{"id": data.Id.ValueString()})
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
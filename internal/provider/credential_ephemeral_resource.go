@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &CredentialEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &CredentialEphemeralResource{}
+
+func NewCredentialEphemeralResource() ephemeral.EphemeralResource {
+	return &CredentialEphemeralResource{}
+}
+
+type CredentialEphemeralResource struct {
+	client *PPSClient.APIClient
+	ctx    *context.Context
+}
+
+type CredentialEphemeralResourceModel struct {
+	CredentialID            types.String `tfsdk:"credential_id"`
+	Id                      types.String `tfsdk:"id"`
+	Name                    types.String `tfsdk:"name"`
+	Username                types.String `tfsdk:"username"`
+	Password                types.String `tfsdk:"password"`
+	Url                     types.String `tfsdk:"url"`
+	Notes                   types.String `tfsdk:"notes"`
+	FolderId                types.String `tfsdk:"folder_id"`
+	CustomFields            types.Map    `tfsdk:"custom_fields"`
+	CustomApplicationFields types.Map    `tfsdk:"custom_application_fields"`
+	OTPCode                 types.String `tfsdk:"otp_code"`
+}
+
+func (e *CredentialEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_credential"
+}
+
+func (e *CredentialEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The `credential` ephemeral resource reads a credential without storing it in the plan or the state, e.g. to configure a provider or set a write-only argument. Requires Terraform 1.10 or later.",
+
+		Attributes: map[string]schema.Attribute{
+			"credential_id": schema.StringAttribute{
+				MarkdownDescription: "The identifier of the credential",
+				Required:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The unique identifier of the credential",
+				Computed:            true,
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the credential",
+				Computed:            true,
+			},
+			"username": schema.StringAttribute{
+				MarkdownDescription: "The username of the credential",
+				Computed:            true,
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "The password of the credential",
+				Sensitive:           true,
+				Computed:            true,
+			},
+			"url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the credential",
+				Computed:            true,
+			},
+			"notes": schema.StringAttribute{
+				MarkdownDescription: "The notes of the credential",
+				Computed:            true,
+			},
+			"folder_id": schema.StringAttribute{
+				MarkdownDescription: "The folder ID of the credential",
+				Computed:            true,
+			},
+			"custom_fields": schema.MapAttribute{
+				MarkdownDescription: "The custom user fields of the credential, all marked as sensitive",
+				ElementType:         types.StringType,
+				Sensitive:           true,
+				Computed:            true,
+			},
+			"custom_application_fields": schema.MapAttribute{
+				MarkdownDescription: "The custom application fields of the credential",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"otp_code": schema.StringAttribute{
+				MarkdownDescription: "The current TOTP code of the credential, computed from the TOTP properties of the credential, or null when it has none or the user may not view it",
+				Sensitive:           true,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (e *CredentialEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerclient, ok := req.ProviderData.(ProviderClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *PPSClient.APIClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	e.client = &providerclient.Client
+	e.ctx = &providerclient.Ctx
+}
+
+func (e *CredentialEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	if failWhileConfigUnknown(e.client, &resp.Diagnostics) {
+		return
+	}
+
+	var data CredentialEphemeralResourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	credential_id := data.CredentialID.ValueString()
+
+	res, httpres, err := e.client.DefaultAPI.GetV6CredentialsByID(*e.ctx, credential_id).Execute()
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "read the credential", permission: "View", object: fmt.Sprintf("credential %q", credential_id)}, httpres, err, 200) {
+		return
+	}
+
+	data.Id = types.StringValue(res.GetId())
+	data.Name = types.StringValue(res.GetName())
+	data.Username = types.StringValue(res.GetUsername())
+	data.Url = types.StringValue(res.GetUrl())
+	data.Notes = types.StringValue(res.GetNotes())
+	data.FolderId = types.StringValue(res.GetGroupId())
+	data.OTPCode = readOTPCode(res, path.Root("otp_code"), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.CustomFields = customFieldsValue(res.CustomUserFields)
+	data.CustomApplicationFields = customFieldsValue(res.CustomApplicationFields)
+
+	pwdres, httpres, err := e.client.DefaultAPI.GetV6CredentialPasswordByID(*e.ctx, credential_id).Execute()
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "read the password of the credential", permission: "View", object: fmt.Sprintf("credential %q", credential_id)}, httpres, err, 200) {
+		return
+	}
+
+	sanitypassword, err := strconv.Unquote(pwdres) // used to remove the quotes and escape characters from the password
+	if err != nil {
+		sanitypassword = pwdres
+	}
+	data.Password = types.StringValue(sanitypassword)
+
+	tflog.Trace(ctx, "Opened credential", map[string]interface{}{"id": data.Id.ValueString()})
+
+	// Save data into the ephemeral result, which is never persisted
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
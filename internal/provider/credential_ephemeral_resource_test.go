@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccCredentialEphemeralResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			// Ephemeral resources are supported from Terraform 1.10.
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		// The echo provider exposes the ephemeral values as a resource, so
+		// that they can be checked.
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"pleasantpassword": providerserver.NewProtocol6WithError(&PleasantpasswordProvider{"test"}),
+			"echo":             echoprovider.NewProviderServer(),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredentialEphemeralResourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("echo.credential_test", "data.name", "acctest_ephemeral"),
+					resource.TestCheckResourceAttr("echo.credential_test", "data.username", "acctest_username1"),
+					resource.TestCheckResourceAttr("echo.credential_test", "data.password", "acctest_password1"),
+					resource.TestCheckResourceAttr("echo.credential_test", "data.custom_fields.acctest_field", "acctest_value"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCredentialEphemeralResourceConfig = `
+data "pleasantpassword_folder_root" "get_root_folder" {
+}
+
+resource "pleasantpassword_credential" "cred1_test" {
+	name = "acctest_ephemeral"
+	folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+	password = "acctest_password1"
+	username = "acctest_username1"
+	custom_fields = {
+		acctest_field = "acctest_value"
+	}
+}
+
+ephemeral "pleasantpassword_credential" "credential_test" {
+	credential_id = pleasantpassword_credential.cred1_test.id
+}
+
+provider "echo" {
+	data = ephemeral.pleasantpassword_credential.credential_test
+}
+
+resource "echo" "credential_test" {
+}
+`
@@ -4,21 +4,45 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	PPSClient "github.com/theochita/go-pleasant-password"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &CredentialResource{}
 var _ resource.ResourceWithImportState = &CredentialResource{}
+var _ resource.ResourceWithUpgradeState = &CredentialResource{}
+var _ resource.ResourceWithValidateConfig = &CredentialResource{}
+var _ resource.ResourceWithModifyPlan = &CredentialResource{}
 
 func NewCredentialResource() resource.Resource {
 	return &CredentialResource{}
@@ -30,17 +54,33 @@ type CredentialResource struct {
 }
 
 type CredentialResourceModel struct {
-	Id types.String `tfsdk:"id"`
-	//Tags     []Tag        `tfsdk:"tags"`
-	Name     types.String `tfsdk:"name"`
-	Username types.String `tfsdk:"username"`
-	Password types.String `tfsdk:"password"`
-	Url      types.String `tfsdk:"url"`
-	Notes    types.String `tfsdk:"notes"`
-	FolderId types.String `tfsdk:"folder_id"`
-	Created  types.String `tfsdk:"created"`
-	Modified types.String `tfsdk:"modified"`
-	Expires  types.String `tfsdk:"expires"`
+	Id                      types.String `tfsdk:"id"`
+	Tags                    types.Set    `tfsdk:"tags"`
+	Name                    types.String `tfsdk:"name"`
+	Username                types.String `tfsdk:"username"`
+	Password                types.String `tfsdk:"password"`
+	Url                     types.String `tfsdk:"url"`
+	Notes                   types.String `tfsdk:"notes"`
+	FolderId                types.String `tfsdk:"folder_id"`
+	Created                 types.String `tfsdk:"created"`
+	Modified                types.String `tfsdk:"modified"`
+	Expires                 types.String `tfsdk:"expires"`
+	CustomFields            types.Map    `tfsdk:"custom_fields"`
+	SensitiveCustomFields   types.Map    `tfsdk:"sensitive_custom_fields"`
+	CustomApplicationFields types.Map    `tfsdk:"custom_application_fields"`
+	GeneratePassword        types.Object `tfsdk:"generate_password"`
+	Keepers                 types.Map    `tfsdk:"keepers"`
+	Rotation                types.Object `tfsdk:"rotation"`
+	LastRotated             types.String `tfsdk:"last_rotated"`
+	StorePasswordInState    types.Bool   `tfsdk:"store_password_in_state"`
+	PasswordSHA256          types.String `tfsdk:"password_sha256"`
+	PasswordWO              types.String `tfsdk:"password_wo"`
+	PasswordWOVersion       types.Int64  `tfsdk:"password_wo_version"`
+	OTPSecret               types.String `tfsdk:"otp_secret"`
+	OTPIssuer               types.String `tfsdk:"otp_issuer"`
+	OTPDigits               types.Int64  `tfsdk:"otp_digits"`
+	OTPPeriod               types.Int64  `tfsdk:"otp_period"`
+	DeletionMode            types.String `tfsdk:"deletion_mode"`
 }
 
 func (r *CredentialResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -51,6 +91,7 @@ func (r *CredentialResource) Schema(ctx context.Context, req resource.SchemaRequ
 	resp.Schema = schema.Schema{
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "The `credential` resource allows you to create and manage credentials in Pleasant Password Server.",
+		Version:             1,
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -70,10 +111,34 @@ func (r *CredentialResource) Schema(ctx context.Context, req resource.SchemaRequ
 				Optional:            true,
 			},
 			"password": schema.StringAttribute{
-				MarkdownDescription: "The password associated with the credential.",
+				MarkdownDescription: "The password associated with the credential. Conflicts with `generate_password`; when neither is set, the current password is kept. Null when `store_password_in_state` is `false`.",
+				Sensitive:           true,
 				Computed:            true,
 				Optional:            true,
 			},
+			"password_wo": schema.StringAttribute{
+				MarkdownDescription: "The password associated with the credential, never stored in the plan or the state. It is sent when the credential is created and when `password_wo_version` changes, which is required. Conflicts with `password` and `generate_password`. Requires Terraform 1.11 or later.",
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+			},
+			"password_wo_version": schema.Int64Attribute{
+				MarkdownDescription: "The version of `password_wo`, to change to update the password. Required with `password_wo`, and only allowed with it.",
+				Optional:            true,
+			},
+			"store_password_in_state": schema.BoolAttribute{
+				MarkdownDescription: "Whether to store the password in the state. When `false`, only `password_sha256` is stored to detect changes, which requires the password to be generated with `generate_password`, set with `password_wo` or left unset. A generated or write-only password changed outside of Terraform is generated or sent again on the next apply, whether or not it is stored. Always `false` with `password_wo`. Defaults to `true`.",
+				Computed:            true,
+				Optional:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"password_sha256": schema.StringAttribute{
+				MarkdownDescription: "The HMAC-SHA256 of the password keyed with the credential ID, in hexadecimal. Null when `store_password_in_state` is `true`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"url": schema.StringAttribute{
 				MarkdownDescription: "The URL associated with the credential.",
 				Computed:            true,
@@ -89,20 +154,503 @@ func (r *CredentialResource) Schema(ctx context.Context, req resource.SchemaRequ
 				Required:            true,
 			},
 			"created": schema.StringAttribute{
-				MarkdownDescription: "The creation timestamp of the credential.",
+				MarkdownDescription: "The creation timestamp of the credential, in RFC 3339 format.",
 				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"modified": schema.StringAttribute{
-				MarkdownDescription: "The last modification timestamp of the credential.",
+				MarkdownDescription: "The last modification timestamp of the credential, in RFC 3339 format.",
 				Computed:            true,
 			},
 			"expires": schema.StringAttribute{
-				MarkdownDescription: "The expiration date of the credential.",
+				MarkdownDescription: "The expiration date of the credential, in RFC 3339 format, e.g. `2030-01-31T00:00:00Z`. Removing it from the configuration keeps the current expiration date. Computed from the last rotation when `rotation` is set.",
+				Computed:            true,
+				Optional:            true,
+				Validators: []validator.String{
+					rfc3339Validator{},
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"tags": schema.SetAttribute{
+				MarkdownDescription: "The names of the tags of the credential. Tags added outside of Terraform are removed unless they are listed. The tags are not managed when the attribute is not set.",
+				ElementType:         types.StringType,
+				Computed:            true,
+				Optional:            true,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"custom_fields": schema.MapAttribute{
+				MarkdownDescription: "The custom user fields of the credential, such as a host or a port. Custom fields added outside of Terraform are removed unless they are listed in this attribute or in `sensitive_custom_fields`. Only the fields listed here are read into this attribute, any other one being read into `sensitive_custom_fields`. The custom fields are not managed when neither attribute is set.",
+				ElementType:         types.StringType,
+				Computed:            true,
+				Optional:            true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"sensitive_custom_fields": schema.MapAttribute{
+				MarkdownDescription: "The custom user fields of the credential whose values are secret, such as an SSH key passphrase. They are managed like `custom_fields`, but hidden from the plan output. Custom fields not listed in `custom_fields`, e.g. on import, are read into this attribute. A key cannot be in both maps.",
+				ElementType:         types.StringType,
+				Sensitive:           true,
+				Computed:            true,
+				Optional:            true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"custom_application_fields": schema.MapAttribute{
+				MarkdownDescription: "The custom application fields of the credential, set by applications integrated with Pleasant Password Server.",
+				ElementType:         types.StringType,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"keepers": schema.MapAttribute{
+				MarkdownDescription: "Arbitrary values that generate a new password when they change, updating the credential in place. Only used with `generate_password`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"deletion_mode": schema.StringAttribute{
+				MarkdownDescription: "What to do with the credential when it is destroyed: `delete` deletes it permanently, `archive` moves it to the archive of the server, from which it can be restored, and `abandon` only removes it from the state. Defaults to `delete`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(deletionModeDelete),
+				Validators: []validator.String{
+					deletionModeValidator{},
+				},
+			},
+			"last_rotated": schema.StringAttribute{
+				MarkdownDescription: "The date the password was last changed by Terraform, in RFC 3339 format.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"otp_secret": schema.StringAttribute{
+				MarkdownDescription: "The base32 secret of the TOTP generator of the credential, as shown by authenticator apps. The server stores no algorithm, the codes are computed with SHA-1. The TOTP generator is not managed when the attribute is not set.",
+				Sensitive:           true,
+				Optional:            true,
 				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"otp_issuer": schema.StringAttribute{
+				MarkdownDescription: "The issuer of the TOTP generator, e.g. the name of the service. Requires `otp_secret`, the current issuer being kept when it is not set.",
 				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"otp_digits": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The number of digits of the codes, between 6 and 10. Defaults to `%d` for a new generator. Requires `otp_secret`, the current number being kept when it is not set.", totpDefaultDigits),
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"otp_period": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The number of seconds a code is valid. Defaults to `%d` for a new generator. Requires `otp_secret`, the current period being kept when it is not set.", totpDefaultPeriod),
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
 			},
 		},
+
+		Blocks: map[string]schema.Block{
+			"generate_password": schema.SingleNestedBlock{
+				MarkdownDescription: "Generates the password when the credential is created, or when `keepers` or the settings of this block change. The password is generated by the provider, as the API does not expose the password generator of the server, so the settings must meet the password policy of the folder.",
+				Attributes: map[string]schema.Attribute{
+					"length": schema.Int64Attribute{
+						MarkdownDescription: fmt.Sprintf("The length of the password, at most `%d`. Defaults to `%d`.", maxPasswordLength, defaultPasswordLength),
+						Optional:            true,
+						Validators: []validator.Int64{
+							passwordLengthValidator{},
+						},
+					},
+					"lower": schema.BoolAttribute{
+						MarkdownDescription: "Whether to include lowercase letters. Defaults to `true`.",
+						Optional:            true,
+					},
+					"upper": schema.BoolAttribute{
+						MarkdownDescription: "Whether to include uppercase letters. Defaults to `true`.",
+						Optional:            true,
+					},
+					"numeric": schema.BoolAttribute{
+						MarkdownDescription: "Whether to include digits. Defaults to `true`.",
+						Optional:            true,
+					},
+					"special": schema.BoolAttribute{
+						MarkdownDescription: fmt.Sprintf("Whether to include the special characters `%s`. Defaults to `true`.", specialCharacters),
+						Optional:            true,
+					},
+					"exclude_characters": schema.StringAttribute{
+						MarkdownDescription: "The characters never to include, e.g. the ones that look alike.",
+						Optional:            true,
+					},
+				},
+			},
+			"rotation": schema.SingleNestedBlock{
+				MarkdownDescription: "Generates a new password on the first plan after `interval` has elapsed since `last_rotated`, and sets `expires` to the date of the next rotation. A credential whose last rotation is not known, e.g. after an import, is rotated on the next apply. Requires `generate_password`.",
+				Attributes: map[string]schema.Attribute{
+					"interval": schema.StringAttribute{
+						MarkdownDescription: "The interval between two rotations, a number of days or weeks such as `90d` or `2w`, or a duration such as `12h`.",
+						Required:            true,
+						Validators: []validator.String{
+							rotationIntervalValidator{},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *CredentialResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data CredentialResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.PasswordWO.IsNull() {
+		if !data.Password.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password_wo"),
+				"Conflicting Password Attributes",
+				"The password cannot be set with both password and password_wo.",
+			)
+		}
+		if !data.GeneratePassword.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password_wo"),
+				"Conflicting Password Attributes",
+				"The password cannot be set with password_wo when it is generated with the generate_password block.",
+			)
+		}
+		if data.StorePasswordInState.ValueBool() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("store_password_in_state"),
+				"Password Stored in State",
+				"A password set with password_wo is never stored in the state.",
+			)
+		}
+		// Without a version, a credential moving from password to
+		// password_wo would never be sent the write-only password.
+		if data.PasswordWOVersion.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password_wo_version"),
+				"Missing Password Version",
+				"The password_wo_version attribute is required with password_wo, as the write-only password is only sent when its version changes.",
+			)
+		}
+	} else if !data.PasswordWOVersion.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password_wo_version"),
+			"Missing Write-Only Password",
+			"The password_wo_version attribute can only be set with password_wo.",
+		)
+	}
+
+	if !data.StorePasswordInState.IsNull() && !data.StorePasswordInState.ValueBool() && !data.Password.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password"),
+			"Password Stored in State",
+			"A configured password is always stored in the state. Set it with password_wo, generate it with the generate_password block or leave it unset to keep it out of the state.",
+		)
+	}
+
+	r.validateOTP(data, &resp.Diagnostics)
+
+	if !data.Rotation.IsNull() {
+		if data.GeneratePassword.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("rotation"),
+				"Missing Password Generation Settings",
+				"The generate_password block is required to rotate the password.",
+			)
+		}
+		if !data.Expires.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("expires"),
+				"Conflicting Expiration Attributes",
+				"The expiration date cannot be set when it is computed from the rotation interval.",
+			)
+		}
+	}
+
+	if data.GeneratePassword.IsNull() || data.GeneratePassword.IsUnknown() {
+		return
+	}
+
+	if !data.Password.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password"),
+			"Conflicting Password Attributes",
+			"The password cannot be set when it is generated with the generate_password block.",
+		)
+	}
+
+	var settings generatePasswordModel
+	resp.Diagnostics.Append(data.GeneratePassword.As(ctx, &settings, basetypes.ObjectAsOptions{})...)
+	if resp.Diagnostics.HasError() || !isKnownPasswordSettings(settings) {
+		return
+	}
+
+	if _, err := newPasswordPolicy(settings); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("generate_password"), "Invalid Password Generation Settings", err.Error()+".")
+	}
+}
+
+// validateOTP checks the otp_* attributes, which all require otp_secret.
+func (r *CredentialResource) validateOTP(data CredentialResourceModel, diags *diag.Diagnostics) {
+	settings := map[string]attr.Value{
+		"otp_issuer": data.OTPIssuer,
+		"otp_digits": data.OTPDigits,
+		"otp_period": data.OTPPeriod,
+	}
+
+	if data.OTPSecret.IsNull() {
+		for name, value := range settings {
+			if !value.IsNull() {
+				diags.AddAttributeError(
+					path.Root(name),
+					"Missing OTP Secret",
+					fmt.Sprintf("The otp_secret attribute is required to set %s.", name),
+				)
+			}
+		}
+		return
+	}
+
+	if data.OTPSecret.IsUnknown() {
+		return
+	}
+	for _, value := range settings {
+		if value.IsUnknown() {
+			return
+		}
+	}
+
+	if _, err := credentialTOTPSettings(data).code(time.Now()); err != nil {
+		diags.AddAttributeError(path.Root("otp_secret"), "Invalid OTP Settings", err.Error()+".")
+	}
+}
+
+// credentialTOTPSettings returns the TOTP settings of the otp_* attributes.
+func credentialTOTPSettings(data CredentialResourceModel) totpSettings {
+	return totpSettings{
+		secret: data.OTPSecret.ValueString(),
+		issuer: data.OTPIssuer.ValueString(),
+		digits: int(data.OTPDigits.ValueInt64()),
+		period: data.OTPPeriod.ValueInt64(),
+	}
+}
+
+// readOTP sets the otp_* attributes from the TOTP generator of the
+// credential returned by the API. They are left as they are when the user
+// may not view the generator, as the API does not return it then.
+func (r *CredentialResource) readOTP(data *CredentialResourceModel, res *PPSClient.V6CredentialResult) {
+	if !canViewTOTP(res) {
+		return
+	}
+
+	settings, ok := apiTOTPSettings(res)
+	if !ok {
+		data.OTPSecret = types.StringNull()
+		data.OTPIssuer = types.StringNull()
+		data.OTPDigits = types.Int64Null()
+		data.OTPPeriod = types.Int64Null()
+		return
+	}
+
+	data.OTPSecret = types.StringValue(settings.secret)
+	data.OTPIssuer = optionalString(settings.issuer)
+	data.OTPDigits = types.Int64Value(int64(settings.digitsOrDefault()))
+	data.OTPPeriod = types.Int64Value(settings.periodOrDefault())
+}
+
+// optionalString returns value, or null when it is empty.
+func optionalString(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+
+	return types.StringValue(value)
+}
+
+// isKnownPasswordSettings reports whether all the settings of the
+// generate_password block are known, so that they can be validated.
+func isKnownPasswordSettings(s generatePasswordModel) bool {
+	return !s.Length.IsUnknown() && !s.Lower.IsUnknown() && !s.Upper.IsUnknown() &&
+		!s.Numeric.IsUnknown() && !s.Special.IsUnknown() && !s.ExcludeCharacters.IsUnknown()
+}
+
+// ModifyPlan plans a new password when it is generated and the credential is
+// created, keepers or the generate_password settings changed, or the rotation
+// is due. A generated or write-only password changed outside of Terraform is
+// generated or sent again. Otherwise a password that is not configured keeps
+// its current value. last_rotated and, with a rotation, expires follow the
+// password.
+func (r *CredentialResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var passwordWO types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password_wo"), &passwordWO)...)
+
+	// A write-only password is never stored in the state.
+	if !passwordWO.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("store_password_in_state"), types.BoolValue(false))...)
+	}
+
+	// Nothing more to plan on create, where the password is unknown.
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan, state CredentialResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	r.planCustomFields(ctx, req, resp, state)
+
+	var password types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password"), &password)...)
+
+	interval, rotated := r.rotationInterval(ctx, plan.Rotation, &resp.Diagnostics)
+
+	applied, diags := req.Private.GetKey(ctx, appliedPasswordKey)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var changed bool
+	switch {
+	case !password.IsNull():
+		changed = !password.Equal(state.Password)
+	case !passwordWO.IsNull():
+		changed = !plan.PasswordWOVersion.Equal(state.PasswordWOVersion) || passwordChangedOutside(applied, state)
+	case !plan.GeneratePassword.IsNull():
+		changed = !plan.GeneratePassword.Equal(state.GeneratePassword) || !plan.Keepers.Equal(state.Keepers) ||
+			(rotated && rotationDue(state.LastRotated, interval, time.Now())) || passwordChangedOutside(applied, state)
+		if changed {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("password"), types.StringUnknown())...)
+		}
+	}
+
+	if changed {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("password_sha256"), plannedPasswordHash(plan, types.StringUnknown()))...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_rotated"), types.StringUnknown())...)
+		if rotated {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("expires"), types.StringUnknown())...)
+		}
+		return
+	}
+
+	if password.IsNull() {
+		switch {
+		case !storePassword(plan):
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("password"), types.StringNull())...)
+		case state.Password.IsNull():
+			// The password was not stored, it is read again.
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("password"), types.StringUnknown())...)
+		default:
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("password"), state.Password)...)
+		}
+	}
+	// The hash is computed on apply when the password stops being stored.
+	hash := state.PasswordSHA256
+	if hash.IsNull() {
+		hash = types.StringUnknown()
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("password_sha256"), plannedPasswordHash(plan, hash))...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_rotated"), state.LastRotated)...)
+
+	// The interval may have changed, the next rotation is planned from the
+	// last one.
+	if t, err := time.Parse(time.RFC3339, state.LastRotated.ValueString()); rotated && err == nil {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("expires"), expiresValue(PPSClient.PtrString(t.Add(interval).Format(time.RFC3339)), state.Expires))...)
+	}
+}
+
+// rotationInterval returns the interval of the rotation block, ok being false
+// when the block is not set.
+func (r *CredentialResource) rotationInterval(ctx context.Context, rotation types.Object, diags *diag.Diagnostics) (interval time.Duration, ok bool) {
+	if rotation.IsNull() || rotation.IsUnknown() {
+		return 0, false
+	}
+
+	var settings rotationModel
+	diags.Append(rotation.As(ctx, &settings, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() || settings.Interval.IsUnknown() {
+		return 0, false
+	}
+
+	interval, err := parseRotationInterval(settings.Interval.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("rotation").AtName("interval"), "Invalid Rotation Interval", err.Error()+".")
+		return 0, false
+	}
+
+	return interval, true
+}
+
+// recordRotation sets last_rotated when the password is planned to change and,
+// with a rotation, expires to the date of the next rotation.
+func (r *CredentialResource) recordRotation(ctx context.Context, data *CredentialResourceModel, diags *diag.Diagnostics) {
+	if !data.LastRotated.IsUnknown() {
+		return
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	data.LastRotated = types.StringValue(now.Format(time.RFC3339))
+
+	if interval, ok := r.rotationInterval(ctx, data.Rotation, diags); ok {
+		data.Expires = types.StringValue(now.Add(interval).Format(time.RFC3339))
+	}
+}
+
+// generatePassword sets the password of data when it is planned to be
+// generated.
+func (r *CredentialResource) generatePassword(ctx context.Context, data *CredentialResourceModel, diags *diag.Diagnostics) {
+	if !data.Password.IsUnknown() || data.GeneratePassword.IsNull() {
+		return
+	}
+
+	var settings generatePasswordModel
+	diags.Append(data.GeneratePassword.As(ctx, &settings, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return
 	}
+
+	policy, err := newPasswordPolicy(settings)
+	if err != nil {
+		diags.AddAttributeError(path.Root("generate_password"), "Invalid Password Generation Settings", err.Error()+".")
+		return
+	}
+
+	password, err := policy.generate()
+	if err != nil {
+		diags.AddError("Unable to generate the password", err.Error())
+		return
+	}
+
+	data.Password = types.StringValue(password)
 }
 
 func (r *CredentialResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
@@ -127,6 +675,328 @@ func (r *CredentialResource) Configure(ctx context.Context, req resource.Configu
 
 }
 
+// tagsToAPI sets the tags of param to the names in the tags attribute of
+// config. An empty set is sent as an empty list, which removes all the tags of
+// the credential, while the tags are left untouched when it is not set.
+func (r *CredentialResource) tagsToAPI(ctx context.Context, config tfsdk.Config, param *PPSClient.V6CredentialInput) diag.Diagnostics {
+	var tags types.Set
+	diags := config.GetAttribute(ctx, path.Root("tags"), &tags)
+	if diags.HasError() || tags.IsNull() {
+		return diags
+	}
+
+	var names []string
+	diags.Append(tags.ElementsAs(ctx, &names, false)...)
+
+	param.Tags = []PPSClient.V6TagResult{}
+	for _, name := range names {
+		param.Tags = append(param.Tags, PPSClient.V6TagResult{Name: PPSClient.PtrString(name)})
+	}
+
+	return diags
+}
+
+func (r *CredentialResource) fetchTags(res []PPSClient.V6TagResult) types.Set {
+	tags := []attr.Value{}
+	for _, v := range res {
+		tags = append(tags, types.StringValue(v.GetName()))
+	}
+
+	return types.SetValueMust(types.StringType, tags)
+}
+
+// readComputed sets the attributes computed by the server after the
+// credential has been created or updated.
+func (r *CredentialResource) readComputed(data *CredentialResourceModel, diags *diag.Diagnostics) {
+	res, httpres, err := r.client.DefaultAPI.GetV6CredentialsByID(*r.ctx, data.Id.ValueString()).Execute()
+	if !checkAPIResponse(diags, apiOperation{action: "read the credential", permission: "View", object: fmt.Sprintf("credential %q", data.Id.ValueString())}, httpres, err, 200) {
+		return
+	}
+
+	data.Created = timestampValue(res.Created)
+	data.Modified = timestampValue(res.Modified)
+	data.Expires = expiresValue(res.Expires, data.Expires)
+	data.CustomApplicationFields = customFieldsValue(res.CustomApplicationFields)
+	if data.Tags.IsUnknown() {
+		data.Tags = r.fetchTags(res.Tags)
+	}
+
+	// The otp_* settings that were not configured are read from the server.
+	var otp CredentialResourceModel
+	r.readOTP(&otp, res)
+	if data.OTPSecret.IsUnknown() {
+		data.OTPSecret = otp.OTPSecret
+	}
+	if data.OTPIssuer.IsUnknown() {
+		data.OTPIssuer = otp.OTPIssuer
+	}
+	if data.OTPDigits.IsUnknown() {
+		data.OTPDigits = otp.OTPDigits
+	}
+	if data.OTPPeriod.IsUnknown() {
+		data.OTPPeriod = otp.OTPPeriod
+	}
+
+	// The custom field maps that were not configured hold the fields that
+	// are not in the other map, as sensitive fields when neither was.
+	switch {
+	case data.CustomFields.IsUnknown() && data.SensitiveCustomFields.IsUnknown():
+		data.CustomFields, data.SensitiveCustomFields = splitCustomFields(res.CustomUserFields, unlistedKeys(res.CustomUserFields, map[string]bool{}))
+	case data.CustomFields.IsUnknown():
+		data.CustomFields, _ = splitCustomFields(res.CustomUserFields, mapKeys(data.SensitiveCustomFields))
+	case data.SensitiveCustomFields.IsUnknown():
+		_, data.SensitiveCustomFields = splitCustomFields(res.CustomUserFields, unlistedKeys(res.CustomUserFields, mapKeys(data.CustomFields)))
+	}
+}
+
+// readPassword returns the current password of the credential.
+func (r *CredentialResource) readPassword(data *CredentialResourceModel, diags *diag.Diagnostics) (string, bool) {
+	pwdres, httpres, err := r.client.DefaultAPI.GetV6CredentialPasswordByID(*r.ctx, data.Id.ValueString()).Execute()
+	if !checkAPIResponse(diags, apiOperation{action: "read the password of the credential", permission: "View", object: fmt.Sprintf("credential %q", data.Id.ValueString())}, httpres, err, 200) {
+		return "", false
+	}
+
+	sanitypassword, err := strconv.Unquote(pwdres) // used to remove the quotes and escape characters from the password
+	if err != nil {
+		sanitypassword = pwdres
+	}
+
+	return sanitypassword, true
+}
+
+// passwordWOToAPI sets the password of param to the write-only password, which
+// is only found in the configuration.
+func (r *CredentialResource) passwordWOToAPI(ctx context.Context, config tfsdk.Config, param *PPSClient.V6CredentialInput) diag.Diagnostics {
+	var passwordWO types.String
+	diags := config.GetAttribute(ctx, path.Root("password_wo"), &passwordWO)
+
+	if !passwordWO.IsNull() && !passwordWO.IsUnknown() {
+		param.Password = passwordWO.ValueStringPointer()
+	}
+
+	return diags
+}
+
+// setPassword sets the password of data when it is stored in the state, and
+// its hash otherwise.
+func (r *CredentialResource) setPassword(data *CredentialResourceModel, password string) {
+	if storePassword(*data) {
+		data.Password = types.StringValue(password)
+		data.PasswordSHA256 = types.StringNull()
+	} else {
+		data.Password = types.StringNull()
+		data.PasswordSHA256 = types.StringValue(passwordHash(data.Id.ValueString(), password))
+	}
+}
+
+// appliedPasswordKey is the private state key holding the hash of the password
+// last sent by Terraform, to tell a change made outside of Terraform when the
+// password itself is not in the state.
+const appliedPasswordKey = "applied_password_sha256"
+
+// appliedPasswordValue returns the private state value of the hash of the
+// password of data, hashing the password when it is stored.
+func appliedPasswordValue(data CredentialResourceModel) []byte {
+	hash := data.PasswordSHA256.ValueString()
+	if !data.Password.IsNull() && !data.Password.IsUnknown() {
+		hash = passwordHash(data.Id.ValueString(), data.Password.ValueString())
+	}
+	value, _ := json.Marshal(hash)
+
+	return value
+}
+
+// passwordChangedOutside reports whether the refreshed password of state is
+// not the one last sent by Terraform, applied being the private state value
+// of its hash. Imported credentials have no such value and are never
+// reported.
+func passwordChangedOutside(applied []byte, state CredentialResourceModel) bool {
+	return applied != nil && !bytes.Equal(applied, appliedPasswordValue(state))
+}
+
+// plannedPasswordHash returns the planned password_sha256 of plan, which is
+// hash unless the password is stored in the state.
+func plannedPasswordHash(plan CredentialResourceModel, hash types.String) types.String {
+	switch {
+	case plan.StorePasswordInState.IsUnknown():
+		return types.StringUnknown()
+	case storePassword(plan):
+		return types.StringNull()
+	}
+
+	return hash
+}
+
+// storePassword reports whether the password of data is stored in the state.
+func storePassword(data CredentialResourceModel) bool {
+	return data.StorePasswordInState.IsNull() || data.StorePasswordInState.IsUnknown() || data.StorePasswordInState.ValueBool()
+}
+
+// passwordHash returns the HMAC-SHA256 of password keyed with the credential
+// ID, so that equal passwords of different credentials do not have the same
+// hash.
+func passwordHash(id string, password string) string {
+	mac := hmac.New(sha256.New, []byte(id))
+	mac.Write([]byte(password))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// customFieldsToAPI sets the custom user fields of param from the
+// custom_fields and sensitive_custom_fields attributes. As the API replaces
+// all the custom fields at once, they are left untouched when neither map is
+// configured, state being nil on create.
+func (r *CredentialResource) customFieldsToAPI(ctx context.Context, config tfsdk.Config, data *CredentialResourceModel, state *CredentialResourceModel, param *PPSClient.V6CredentialInput) diag.Diagnostics {
+	var plain, sensitive types.Map
+	diags := config.GetAttribute(ctx, path.Root("custom_fields"), &plain)
+	diags.Append(config.GetAttribute(ctx, path.Root("sensitive_custom_fields"), &sensitive)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	if plain.IsNull() && sensitive.IsNull() {
+		return diags
+	}
+
+	// A map left unknown by the plan keeps the fields of the credential that
+	// are not in the other map.
+	current := map[string]interface{}{}
+	if state != nil && (data.CustomFields.IsUnknown() || data.SensitiveCustomFields.IsUnknown()) {
+		res, httpres, err := r.client.DefaultAPI.GetV6CredentialsByID(*r.ctx, data.Id.ValueString()).Execute()
+		if !checkAPIResponse(&diags, apiOperation{action: "read the credential", permission: "View", object: fmt.Sprintf("credential %q", data.Id.ValueString())}, httpres, err, 200) {
+			return diags
+		}
+		current = res.CustomUserFields
+	}
+
+	fields, fieldsDiags := customFieldsToAPI(ctx, knownMap(data.CustomFields), knownMap(data.SensitiveCustomFields))
+	diags.Append(fieldsDiags...)
+	for key, value := range current {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	param.CustomUserFields = fields
+
+	return diags
+}
+
+// totpToAPI returns the TOTP generator to send for data, or nil when it did
+// not change, state being nil on create. The generator is left untouched when
+// otp_secret is not set in config, the other settings keeping their planned
+// value, which is the current one when they are not set either.
+func (r *CredentialResource) totpToAPI(ctx context.Context, config tfsdk.Config, data CredentialResourceModel, state *CredentialResourceModel) (*totpSettings, diag.Diagnostics) {
+	var secret types.String
+	diags := config.GetAttribute(ctx, path.Root("otp_secret"), &secret)
+	if diags.HasError() || secret.IsNull() {
+		return nil, diags
+	}
+
+	if state != nil && data.OTPSecret.Equal(state.OTPSecret) && data.OTPIssuer.Equal(state.OTPIssuer) &&
+		data.OTPDigits.Equal(state.OTPDigits) && data.OTPPeriod.Equal(state.OTPPeriod) {
+		return nil, diags
+	}
+
+	// The settings that are unknown on create are sent as zero values,
+	// standing for the defaults.
+	settings := credentialTOTPSettings(data)
+
+	return &settings, diags
+}
+
+// credentialInput is the body of the requests creating and updating a
+// credential with its TOTP generator. The generated V6CredentialInput has no
+// TOTP properties, so they are added to it and the requests go through
+// callAPI.
+type credentialInput struct {
+	*PPSClient.V6CredentialInput
+	totp totpSettings
+}
+
+func (c credentialInput) MarshalJSON() ([]byte, error) {
+	body, err := c.V6CredentialInput.ToMap()
+	if err != nil {
+		return nil, err
+	}
+
+	// The defaults are sent as well, so that a setting removed from the
+	// configuration does not keep its previous value.
+	body["TOTPSecret"] = c.totp.secret
+	body["TOTPIssuer"] = c.totp.issuer
+	body["TOTPDigits"] = c.totp.digitsOrDefault()
+	body["TOTPPeriod"] = c.totp.periodOrDefault()
+
+	return json.Marshal(body)
+}
+
+// createCredential creates a credential and returns its ID, totp being the
+// TOTP generator to set, if any.
+func (r *CredentialResource) createCredential(param *PPSClient.V6CredentialInput, totp *totpSettings) (string, *http.Response, error) {
+	if totp == nil {
+		return r.client.DefaultAPI.PostV6Credentials(*r.ctx).V6CredentialInput(*param).Execute()
+	}
+
+	var res string
+	httpres, err := callAPI(*r.ctx, r.client, http.MethodPost, "/api/v6/rest/entries", credentialInput{param, *totp}, &res)
+
+	return res, httpres, err
+}
+
+// updateCredential updates a credential, totp being the TOTP generator to
+// set, if it changed.
+func (r *CredentialResource) updateCredential(id string, param *PPSClient.V6CredentialInput, totp *totpSettings) (*http.Response, error) {
+	if totp == nil {
+		return r.client.DefaultAPI.PatchV6CredentialsByID(*r.ctx, id).V6CredentialInput(*param).Execute()
+	}
+
+	return callAPI(*r.ctx, r.client, http.MethodPatch, "/api/v6/rest/entries/"+url.PathEscape(id), credentialInput{param, *totp}, nil)
+}
+
+// knownMap returns m, or a null map when m is unknown.
+func knownMap(m types.Map) types.Map {
+	if m.IsUnknown() {
+		return types.MapNull(types.StringType)
+	}
+
+	return m
+}
+
+// planCustomFields plans the custom field map that is not configured when the
+// other one is. The keys of the configured map are removed from it, as they
+// are moved to the configured map.
+func (r *CredentialResource) planCustomFields(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, state CredentialResourceModel) {
+	var plain, sensitive types.Map
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("custom_fields"), &plain)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("sensitive_custom_fields"), &sensitive)...)
+
+	switch {
+	case !plain.IsNull() && sensitive.IsNull():
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sensitive_custom_fields"), withoutKeys(state.SensitiveCustomFields, plain))...)
+	case plain.IsNull() && !sensitive.IsNull():
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("custom_fields"), withoutKeys(state.CustomFields, sensitive))...)
+	}
+}
+
+// withoutKeys returns m without the keys of other, or an unknown map when
+// either is unknown.
+func withoutKeys(m types.Map, other types.Map) types.Map {
+	if m.IsUnknown() || other.IsUnknown() {
+		return types.MapUnknown(types.StringType)
+	}
+	if m.IsNull() {
+		return m
+	}
+
+	elements := map[string]attr.Value{}
+	for key, value := range m.Elements() {
+		if _, ok := other.Elements()[key]; !ok {
+			elements[key] = value
+		}
+	}
+
+	return types.MapValueMust(types.StringType, elements)
+}
+
 func (r *CredentialResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data CredentialResourceModel
 
@@ -137,23 +1007,32 @@ func (r *CredentialResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
+	r.generatePassword(ctx, &data, &resp.Diagnostics)
+	r.recordRotation(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	param := PPSClient.NewV6CredentialInputWithDefaults()
 	param.Name = data.Name.ValueStringPointer()
 	param.Notes = data.Notes.ValueStringPointer()
 	param.GroupId = data.FolderId.ValueStringPointer()
 	param.Username = data.Username.ValueStringPointer()
 	param.Password = data.Password.ValueStringPointer()
+	resp.Diagnostics.Append(r.passwordWOToAPI(ctx, req.Config, param)...)
 	param.Url = data.Url.ValueStringPointer()
+	param.Expires = expiresToAPI(data.Expires)
+	resp.Diagnostics.Append(r.tagsToAPI(ctx, req.Config, param)...)
+	resp.Diagnostics.Append(r.customFieldsToAPI(ctx, req.Config, &data, nil, param)...)
+	totp, diags := r.totpToAPI(ctx, req.Config, data, nil)
+	resp.Diagnostics.Append(diags...)
 
-	// expire and tags not implemented
-
-	res, httpres, err := r.client.DefaultAPI.PostV6Credentials(*r.ctx).V6CredentialInput(*param).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	if httpres.StatusCode != 200 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+
+	res, httpres, err := r.createCredential(param, totp)
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "create the credential", permission: "Add", object: fmt.Sprintf("folder %q", data.FolderId.ValueString()), fields: credentialFields}, httpres, err, 200) {
 		return
 	}
 
@@ -171,18 +1050,22 @@ func (r *CredentialResource) Create(ctx context.Context, req resource.CreateRequ
 	data.Notes = types.StringValue(param.GetNotes())
 	data.FolderId = types.StringValue(param.GetGroupId())
 	data.Username = types.StringValue(param.GetUsername())
-	data.Password = types.StringValue(param.GetPassword())
 	data.Url = types.StringValue(param.GetUrl())
-	data.Created = types.StringValue("Not implemented")
-	data.Modified = types.StringValue("Not implemented")
-	data.Expires = types.StringValue("Not implemented")
-	//data.Tags = types.ListValue(param.GetTags())
+	r.setPassword(&data, param.GetPassword())
+	if param.Password != nil {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, appliedPasswordKey, appliedPasswordValue(data))...)
+	}
+	r.readComputed(&data, &resp.Diagnostics)
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *CredentialResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if skipReadWhileConfigUnknown(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data CredentialResourceModel
 
 	// Read Terraform prior state data into the model
@@ -194,12 +1077,12 @@ func (r *CredentialResource) Read(ctx context.Context, req resource.ReadRequest,
 
 	res, httpres, err := r.client.DefaultAPI.GetV6CredentialsByID(*r.ctx, data.Id.ValueString()).Execute()
 
-	if err != nil {
+	if isNotFound(httpres) {
+		tflog.Warn(ctx, "Credential not found, removing it from the state", map[string]interface{}{"id": data.Id.ValueString()})
 		resp.State.RemoveResource(ctx)
 		return
 	}
-	if httpres.StatusCode != 200 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "read the credential", permission: "View", object: fmt.Sprintf("credential %q", data.Id.ValueString())}, httpres, err, 200) {
 		return
 	}
 
@@ -212,57 +1095,78 @@ func (r *CredentialResource) Read(ctx context.Context, req resource.ReadRequest,
 	data.Url = types.StringValue(res.GetUrl())
 	data.Notes = types.StringValue(res.GetNotes())
 	data.FolderId = types.StringValue(res.GetGroupId())
-	data.Created = types.StringValue("Not implemented")
-	data.Modified = types.StringValue("Not implemented")
-	data.Expires = types.StringValue("Not implemented")
-	//data.Tags = r.fetchTags(res.Tags)
+	data.Created = timestampValue(res.Created)
+	data.Modified = timestampValue(res.Modified)
+	data.Expires = expiresValue(res.Expires, data.Expires)
+	data.Tags = r.fetchTags(res.Tags)
+	r.readOTP(&data, res)
+	// The fields that were plain stay plain, any other one is read as a
+	// sensitive custom field.
+	data.CustomFields, data.SensitiveCustomFields = splitCustomFields(res.CustomUserFields, unlistedKeys(res.CustomUserFields, mapKeys(data.CustomFields)))
+	data.CustomApplicationFields = customFieldsValue(res.CustomApplicationFields)
 
-	pwdres, httpres, err := r.client.DefaultAPI.GetV6CredentialPasswordByID(*r.ctx, data.Id.ValueString()).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
-		return
-	}
-	if httpres.StatusCode != 200 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
-		return
+	// States written before store_password_in_state existed and imported
+	// credentials store the password.
+	if data.StorePasswordInState.IsNull() {
+		data.StorePasswordInState = types.BoolValue(true)
 	}
+	data.DeletionMode = deletionModeValue(data.DeletionMode)
 
-	sanitypassword, err := strconv.Unquote(pwdres) // used to remove the quotes and escape characters from the password
-	if err != nil {
-		sanitypassword = pwdres
+	password, ok := r.readPassword(&data, &resp.Diagnostics)
+	if !ok {
+		return
 	}
-	data.Password = types.StringValue(sanitypassword)
+	r.setPassword(&data, password)
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *CredentialResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data CredentialResourceModel
+	var data, state CredentialResourceModel
 
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	r.generatePassword(ctx, &data, &resp.Diagnostics)
+	r.recordRotation(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	param := PPSClient.NewV6CredentialInputWithDefaults()
 	param.Name = data.Name.ValueStringPointer()
 	param.Notes = data.Notes.ValueStringPointer()
 	param.GroupId = data.FolderId.ValueStringPointer()
 	param.Username = data.Username.ValueStringPointer()
-	param.Password = data.Password.ValueStringPointer()
+	// An unknown password is not changed, it is read back afterwards.
+	if !data.Password.IsUnknown() {
+		param.Password = data.Password.ValueStringPointer()
+	}
+	// The write-only password is sent again when it was changed outside of
+	// Terraform, ModifyPlan then leaving password_sha256 unknown.
+	if !data.PasswordWOVersion.Equal(state.PasswordWOVersion) || data.PasswordSHA256.IsUnknown() {
+		resp.Diagnostics.Append(r.passwordWOToAPI(ctx, req.Config, param)...)
+	}
 	param.Url = data.Url.ValueStringPointer()
+	param.Expires = expiresToAPI(data.Expires)
+	resp.Diagnostics.Append(r.tagsToAPI(ctx, req.Config, param)...)
+	resp.Diagnostics.Append(r.customFieldsToAPI(ctx, req.Config, &data, &state, param)...)
+	totp, diags := r.totpToAPI(ctx, req.Config, data, &state)
+	resp.Diagnostics.Append(diags...)
 
-	httpres, err := r.client.DefaultAPI.PatchV6CredentialsByID(*r.ctx, data.Id.ValueString()).V6CredentialInput(*param).Execute()
-
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	if httpres.StatusCode != 204 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+
+	httpres, err := r.updateCredential(data.Id.ValueString(), param, totp)
+
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "update the credential", permission: "Modify", object: fmt.Sprintf("credential %q", data.Id.ValueString()), fields: credentialFields}, httpres, err, 204) {
 		return
 	}
 
@@ -274,11 +1178,14 @@ func (r *CredentialResource) Update(ctx context.Context, req resource.UpdateRequ
 	data.Notes = types.StringValue(param.GetNotes())
 	data.FolderId = types.StringValue(param.GetGroupId())
 	data.Username = types.StringValue(param.GetUsername())
-	data.Password = types.StringValue(param.GetPassword())
 	data.Url = types.StringValue(param.GetUrl())
-	data.Created = types.StringValue("Not implemented")
-	data.Modified = types.StringValue("Not implemented")
-	data.Expires = types.StringValue("Not implemented")
+	if param.Password != nil {
+		r.setPassword(&data, param.GetPassword())
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, appliedPasswordKey, appliedPasswordValue(data))...)
+	} else if password, ok := r.readPassword(&data, &resp.Diagnostics); ok {
+		r.setPassword(&data, password)
+	}
+	r.readComputed(&data, &resp.Diagnostics)
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -296,14 +1203,26 @@ func (r *CredentialResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
-	httpres, err := r.client.DefaultAPI.DeleteV6CredentialsByID(*r.ctx, data.Id.ValueString()).Execute()
+	var httpres *http.Response
+	var err error
+	op := apiOperation{action: "delete the credential", permission: "Delete", object: fmt.Sprintf("credential %q", data.Id.ValueString())}
 
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
+	switch deletionModeValue(data.DeletionMode).ValueString() {
+	case deletionModeAbandon:
+		tflog.Warn(ctx, "Abandoning credential, it is kept on the server", map[string]interface{}{"id": data.Id.ValueString()})
+		return
+	case deletionModeArchive:
+		op = apiOperation{action: "archive the credential", permission: "Archive", object: fmt.Sprintf("credential %q", data.Id.ValueString())}
+		httpres, err = deleteCredential(*r.ctx, r.client, data.Id.ValueString(), deleteActionArchive)
+	default:
+		httpres, err = deleteCredential(*r.ctx, r.client, data.Id.ValueString(), deleteActionDelete)
+	}
+
+	// The credential was already deleted outside of Terraform.
+	if isNotFound(httpres) {
 		return
 	}
-	if httpres.StatusCode != 204 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	if !checkAPIResponse(&resp.Diagnostics, op, httpres, err, 200, 204) {
 		return
 	}
 
@@ -313,6 +1232,54 @@ func (r *CredentialResource) Delete(ctx context.Context, req resource.DeleteRequ
 
 }
 
+func (r *CredentialResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 stored placeholders instead of the dates.
+		0: {StateUpgrader: upgradePlaceholderDates},
+	}
+}
+
 func (r *CredentialResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// not implemented
+	// Import by credential ID
+	if isGUID(req.ID) {
+		resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+		return
+	}
+
+	// Import by folder path, the last segment being the credential name
+	segments := splitFolderPath(req.ID)
+	if len(segments) < 2 {
+		resp.Diagnostics.AddError(
+			"Invalid Import Identifier",
+			fmt.Sprintf("Expected a credential ID or a path such as Root/Folder/Credential, got: %q", req.ID),
+		)
+		return
+	}
+
+	folder, err := resolveFolderPath(*r.ctx, r.client, segments[:len(segments)-1])
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to resolve the folder path", err.Error())
+		return
+	}
+
+	name := segments[len(segments)-1]
+
+	var matches []string
+	for _, v := range folder.GetCredentials() {
+		if v.GetName() == name {
+			matches = append(matches, v.GetId())
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		resp.Diagnostics.AddError("Credential not found", fmt.Sprintf("No credential named %q exists in folder %q", name, strings.Join(segments[:len(segments)-1], "/")))
+		return
+	case 1:
+	default:
+		resp.Diagnostics.AddError("Ambiguous credential path", fmt.Sprintf("%q matches the credentials %s, import by ID instead", req.ID, strings.Join(matches, ", ")))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), matches[0])...)
 }
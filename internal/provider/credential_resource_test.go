@@ -4,10 +4,25 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	PPSClient "github.com/theochita/go-pleasant-password"
 )
 
 func TestAccCredentialResource(t *testing.T) {
@@ -21,8 +36,32 @@ func TestAccCredentialResource(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "name", "acctest_credentialone"),
 					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "password", "acctest_passwordone"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "tags.#", "2"),
+					resource.TestCheckTypeSetElemAttr("pleasantpassword_credential.cred1_test", "tags.*", "acctest_tagone"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "custom_fields.acctest_field", "acctest_valueone"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "sensitive_custom_fields.acctest_secret", "acctest_secretone"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "expires", "2099-01-31T00:00:00Z"),
+					resource.TestMatchResourceAttr("pleasantpassword_credential.cred1_test", "created", testAccRFC3339Regexp),
+					resource.TestMatchResourceAttr("pleasantpassword_credential.cred1_test", "modified", testAccRFC3339Regexp),
 				),
 			},
+			// ImportState testing
+			{
+				ResourceName:      "pleasantpassword_credential.cred1_test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// Imported custom fields are all read into sensitive_custom_fields, and the
+				// last rotation of an imported credential is not known.
+				ImportStateVerifyIgnore: []string{"custom_fields", "sensitive_custom_fields", "last_rotated"},
+			},
+			// ImportState by folder path testing
+			{
+				ResourceName:            "pleasantpassword_credential.cred1_test",
+				ImportState:             true,
+				ImportStateIdFunc:       testAccCredentialImportStateIdFunc("acctest_credentialone"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"custom_fields", "sensitive_custom_fields", "last_rotated"},
+			},
 
 			// Update and Read testing
 			{
@@ -30,6 +69,13 @@ func TestAccCredentialResource(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "name", "acctest_credentialtwo"),
 					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "password", "acctest_passwordtwo"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "tags.#", "2"),
+					resource.TestCheckTypeSetElemAttr("pleasantpassword_credential.cred1_test", "tags.*", "acctest_tagtwo"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "custom_fields.acctest_field", "acctest_valuetwo"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "sensitive_custom_fields.acctest_secret", "acctest_secrettwo"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.cred1_test", "expires", "2099-01-31T00:00:00Z"),
+					resource.TestMatchResourceAttr("pleasantpassword_credential.cred1_test", "created", testAccRFC3339Regexp),
+					resource.TestMatchResourceAttr("pleasantpassword_credential.cred1_test", "modified", testAccRFC3339Regexp),
 				),
 			},
 			// Delete testing automatically occurs in TestCase
@@ -37,12 +83,695 @@ func TestAccCredentialResource(t *testing.T) {
 	})
 }
 
+func TestCredentialResourceReadRemovesOnlyMissingCredential(t *testing.T) {
+	status := http.StatusNotFound
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"Message":"An error has occurred."}`))
+	})
+
+	resp := testResourceRead(t, NewCredentialResource(), client, "00000000-0000-0000-0000-000000000042")
+	if resp.Diagnostics.HasError() || !resp.State.Raw.IsNull() {
+		t.Fatalf("expected a missing credential to be removed from the state, got: %v", resp.Diagnostics)
+	}
+
+	status = http.StatusInternalServerError
+	resp = testResourceRead(t, NewCredentialResource(), client, "00000000-0000-0000-0000-000000000042")
+	if !resp.Diagnostics.HasError() || resp.State.Raw.IsNull() {
+		t.Fatalf("expected a server error to be reported and the credential to be kept, got: %v", resp.Diagnostics)
+	}
+}
+
+func TestCredentialResourceUpdateCredentialSendsTOTP(t *testing.T) {
+	var method, path string
+	var body map[string]interface{}
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("unexpected body: %s", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	ctx := context.Background()
+	r := &CredentialResource{client: client, ctx: &ctx}
+
+	// The state holds the generator set on the server, e.g. in the web UI.
+	state := CredentialResourceModel{OTPSecret: types.StringValue("JBSWY3DPEHPK3PXP"), OTPIssuer: types.StringNull(), OTPDigits: types.Int64Value(6), OTPPeriod: types.Int64Value(30)}
+	configured := testResourceConfig(t, r, map[string]tftypes.Value{"otp_secret": tftypes.NewValue(tftypes.String, "JBSWY3DPEHPK3PXP")})
+
+	if totp, diags := r.totpToAPI(ctx, configured, state, &state); totp != nil || diags.HasError() {
+		t.Fatalf("expected an unchanged TOTP generator not to be sent, got %+v, %v", totp, diags)
+	}
+
+	data := state
+	data.OTPDigits = types.Int64Value(8)
+	totp, diags := r.totpToAPI(ctx, configured, data, &state)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	param := PPSClient.NewV6CredentialInput()
+	param.SetName("db")
+	httpres, err := r.updateCredential("42", param, totp)
+	if err != nil || httpres.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected response: %v, %v", httpres, err)
+	}
+	if method != http.MethodPatch || path != "/api/v6/rest/entries/42" {
+		t.Errorf("unexpected request %s %s", method, path)
+	}
+	if body["Name"] != "db" || body["TOTPSecret"] != "JBSWY3DPEHPK3PXP" || body["TOTPDigits"] != float64(8) || body["TOTPPeriod"] != float64(totpDefaultPeriod) {
+		t.Errorf("unexpected body %v", body)
+	}
+}
+
+func TestCredentialResourceTOTPToAPILeavesUnconfiguredGenerator(t *testing.T) {
+	ctx := context.Background()
+	r := &CredentialResource{}
+
+	// A generator set on the server is read into the state, and planned
+	// from it while otp_secret is not configured.
+	state := CredentialResourceModel{OTPSecret: types.StringValue("JBSWY3DPEHPK3PXP"), OTPIssuer: types.StringValue("Example"), OTPDigits: types.Int64Value(6), OTPPeriod: types.Int64Value(30)}
+	data := state
+
+	totp, diags := r.totpToAPI(ctx, testResourceConfig(t, r, nil), data, &state)
+	if diags.HasError() || totp != nil {
+		t.Fatalf("expected the TOTP generator not to be sent, got %+v, %v", totp, diags)
+	}
+
+	// Nor is it sent on create.
+	if totp, _ = r.totpToAPI(ctx, testResourceConfig(t, r, nil), data, nil); totp != nil {
+		t.Fatalf("expected no TOTP generator to be sent on create, got %+v", totp)
+	}
+}
+
+func TestCredentialResourceSetPassword(t *testing.T) {
+	r := &CredentialResource{}
+
+	stored := CredentialResourceModel{Id: types.StringValue("42"), StorePasswordInState: types.BoolValue(true)}
+	r.setPassword(&stored, "s3cr3t")
+	if stored.Password.ValueString() != "s3cr3t" {
+		t.Errorf("expected the password to be stored, got %s", stored.Password)
+	}
+	if !stored.PasswordSHA256.IsNull() {
+		t.Errorf("expected the password not to be hashed when it is stored, got %s", stored.PasswordSHA256)
+	}
+
+	hashed := CredentialResourceModel{Id: types.StringValue("42"), StorePasswordInState: types.BoolValue(false)}
+	r.setPassword(&hashed, "s3cr3t")
+	if !hashed.Password.IsNull() {
+		t.Errorf("expected the password not to be stored, got %s", hashed.Password)
+	}
+	if hashed.PasswordSHA256.ValueString() != passwordHash("42", "s3cr3t") || len(hashed.PasswordSHA256.ValueString()) != 64 {
+		t.Errorf("unexpected password hash %s", hashed.PasswordSHA256)
+	}
+
+	// The hash is salted with the credential ID.
+	if passwordHash("43", "s3cr3t") == hashed.PasswordSHA256.ValueString() {
+		t.Error("expected the same password of two credentials to have different hashes")
+	}
+}
+
+func TestCredentialResourcePasswordChangedOutside(t *testing.T) {
+	r := &CredentialResource{}
+
+	state := CredentialResourceModel{Id: types.StringValue("42"), StorePasswordInState: types.BoolValue(false)}
+	r.setPassword(&state, "s3cr3t")
+	applied := appliedPasswordValue(state)
+
+	if passwordChangedOutside(applied, state) {
+		t.Error("expected the password applied by Terraform not to be reported")
+	}
+	if passwordChangedOutside(nil, state) {
+		t.Error("expected an imported credential not to be reported")
+	}
+
+	r.setPassword(&state, "changed")
+	if !passwordChangedOutside(applied, state) {
+		t.Error("expected a password changed outside of Terraform to be reported")
+	}
+
+	// A stored password is compared through its hash as well.
+	stored := CredentialResourceModel{Id: types.StringValue("42"), StorePasswordInState: types.BoolValue(true)}
+	r.setPassword(&stored, "s3cr3t")
+	if passwordChangedOutside(applied, stored) {
+		t.Error("expected the stored password applied by Terraform not to be reported")
+	}
+}
+
+func TestCredentialResourceTagsToAPI(t *testing.T) {
+	r := &CredentialResource{}
+
+	for _, tags := range [][]string{{}, {"team", "prod"}} {
+		values := []tftypes.Value{}
+		for _, tag := range tags {
+			values = append(values, tftypes.NewValue(tftypes.String, tag))
+		}
+
+		param := PPSClient.NewV6CredentialInput()
+		config := testResourceConfig(t, r, map[string]tftypes.Value{"tags": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, values)})
+		if diags := r.tagsToAPI(context.Background(), config, param); diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+
+		// An empty list must be sent to remove the tags of the credential.
+		body, err := json.Marshal(param)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sent struct{ Tags *[]PPSClient.V6TagResult }
+		if err := json.Unmarshal(body, &sent); err != nil {
+			t.Fatal(err)
+		}
+		if sent.Tags == nil || len(*sent.Tags) != len(tags) {
+			t.Fatalf("expected %d tags to be sent, got %s", len(tags), body)
+		}
+	}
+
+	// Tags that are not configured are not managed.
+	param := PPSClient.NewV6CredentialInput()
+	if diags := r.tagsToAPI(context.Background(), testResourceConfig(t, r, nil), param); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if param.Tags != nil {
+		t.Fatalf("expected no tags to be sent, got %v", param.Tags)
+	}
+}
+
+func TestCredentialResourceCustomFieldsToAPI(t *testing.T) {
+	ctx := context.Background()
+	r := &CredentialResource{}
+	fields := types.MapValueMust(types.StringType, map[string]attr.Value{"host": types.StringValue("db.example.com")})
+	state := CredentialResourceModel{Name: types.StringValue("db"), CustomFields: fields, SensitiveCustomFields: types.MapValueMust(types.StringType, nil)}
+
+	// Custom fields that are not configured are not managed.
+	param := PPSClient.NewV6CredentialInput()
+	data := state
+	if diags := r.customFieldsToAPI(ctx, testResourceConfig(t, r, nil), &data, &state, param); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if param.CustomUserFields != nil {
+		t.Fatalf("expected no custom fields to be sent, got %v", param.CustomUserFields)
+	}
+
+	config := testResourceConfig(t, r, map[string]tftypes.Value{
+		"custom_fields": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"host": tftypes.NewValue(tftypes.String, "db.example.com")}),
+	})
+	if diags := r.customFieldsToAPI(ctx, config, &data, &state, param); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if len(param.CustomUserFields) != 1 || param.CustomUserFields["host"] != "db.example.com" {
+		t.Fatalf("expected the configured custom fields to be sent, got %v", param.CustomUserFields)
+	}
+}
+
+func TestCredentialResourceValidatePasswordWOVersion(t *testing.T) {
+	r := &CredentialResource{}
+	cases := map[string]struct {
+		values  map[string]tftypes.Value
+		invalid bool
+	}{
+		"version": {
+			values: map[string]tftypes.Value{
+				"password_wo":         tftypes.NewValue(tftypes.String, "s3cr3t"),
+				"password_wo_version": tftypes.NewValue(tftypes.Number, 1),
+			},
+		},
+		"missing version": {
+			values: map[string]tftypes.Value{
+				"password_wo": tftypes.NewValue(tftypes.String, "s3cr3t"),
+			},
+			invalid: true,
+		},
+		"version alone": {
+			values: map[string]tftypes.Value{
+				"password_wo_version": tftypes.NewValue(tftypes.Number, 1),
+			},
+			invalid: true,
+		},
+	}
+
+	for name, c := range cases {
+		resp := &fwresource.ValidateConfigResponse{}
+		r.ValidateConfig(context.Background(), fwresource.ValidateConfigRequest{Config: testResourceConfig(t, r, c.values)}, resp)
+		if resp.Diagnostics.HasError() != c.invalid {
+			t.Errorf("%s: unexpected diagnostics: %v", name, resp.Diagnostics)
+		}
+	}
+}
+
+func TestWithoutKeys(t *testing.T) {
+	m := types.MapValueMust(types.StringType, map[string]attr.Value{"host": types.StringValue("db"), "port": types.StringValue("5432")})
+	other := types.MapValueMust(types.StringType, map[string]attr.Value{"port": types.StringValue("5433")})
+
+	if got := withoutKeys(m, other); len(got.Elements()) != 1 || got.Elements()["host"] == nil {
+		t.Errorf("expected only the host to be kept, got %s", got)
+	}
+	if got := withoutKeys(m, types.MapUnknown(types.StringType)); !got.IsUnknown() {
+		t.Errorf("expected an unknown map, got %s", got)
+	}
+}
+
+func TestAccCredentialResourceDeletedOutsideTerraform(t *testing.T) {
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredentialResourceConfig("one"),
+				Check:  testAccResourceID("pleasantpassword_credential.cred1_test", &id),
+			},
+			// The credential is planned to be created again
+			{
+				PreConfig: func() {
+					client, ctx := testAccClient(t)
+					if _, err := client.DefaultAPI.DeleteV6CredentialsByID(ctx, id).Execute(); err != nil {
+						t.Fatalf("unable to delete the credential: %s", err)
+					}
+				},
+				Config:             testAccCredentialResourceConfig("one"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccCredentialResourceDeletionModeAbandon(t *testing.T) {
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		// The abandoned credential is still on the server
+		CheckDestroy: func(s *terraform.State) error {
+			client, ctx := testAccClient(t)
+			if _, _, err := client.DefaultAPI.GetV6CredentialsByID(ctx, id).Execute(); err != nil {
+				return fmt.Errorf("expected the abandoned credential to be kept: %w", err)
+			}
+			_, err := client.DefaultAPI.DeleteV6CredentialsByID(ctx, id).Execute()
+
+			return err
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredentialResourceDeletionModeConfig(deletionModeAbandon),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pleasantpassword_credential.deletion_test", "deletion_mode", deletionModeAbandon),
+					testAccResourceID("pleasantpassword_credential.deletion_test", &id),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCredentialResourceDeletionModeArchive(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredentialResourceDeletionModeConfig(deletionModeArchive),
+				Check:  resource.TestCheckResourceAttr("pleasantpassword_credential.deletion_test", "deletion_mode", deletionModeArchive),
+			},
+			// Archive testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccCredentialResourceDeletionModeConfig(mode string) string {
+	return fmt.Sprintf(`
+data "pleasantpassword_folder_root" "get_root_folder" {
+}
+
+resource "pleasantpassword_credential" "deletion_test" {
+	name = "acctest_deletion_%[1]s"
+	folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+	deletion_mode = "%[1]s"
+}
+`, mode)
+}
+
+func TestAccCredentialResourceReadFailureKeepsState(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredentialResourceConfig("one"),
+			},
+			// Refreshing fails with the wrong password
+			{
+				Config:      testAccProviderWrongPasswordConfig + testAccCredentialResourceConfig("one"),
+				ExpectError: regexp.MustCompile(`Unable to read the`),
+			},
+			// The credential is still in the state, nothing is planned
+			{
+				Config:   testAccCredentialResourceConfig("one"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccCredentialResourceGeneratePassword(t *testing.T) {
+	var password, id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredentialResourceGeneratePasswordConfig("one"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceID("pleasantpassword_credential.generated_test", &id),
+					resource.TestCheckResourceAttrWith("pleasantpassword_credential.generated_test", "password", func(value string) error {
+						if len(value) != 24 || strings.ContainsAny(value, specialCharacters) {
+							return fmt.Errorf("unexpected generated password %q", value)
+						}
+						password = value
+						return nil
+					}),
+				),
+			},
+			// The password is kept while the keepers do not change.
+			{
+				Config:   testAccCredentialResourceGeneratePasswordConfig("one"),
+				PlanOnly: true,
+			},
+			// Changing the keepers generates a new password in place.
+			{
+				Config: testAccCredentialResourceGeneratePasswordConfig("two"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("pleasantpassword_credential.generated_test", "id", &id),
+					resource.TestCheckResourceAttrWith("pleasantpassword_credential.generated_test", "password", func(value string) error {
+						if value == password {
+							return fmt.Errorf("expected a new password, got %q again", value)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCredentialResourceRotation(t *testing.T) {
+	var password string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredentialResourceRotationConfig("90d"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("pleasantpassword_credential.rotated_test", "last_rotated", testAccRFC3339Regexp),
+					testAccCheckRotationExpires("pleasantpassword_credential.rotated_test", 90*24*time.Hour),
+					resource.TestCheckResourceAttrWith("pleasantpassword_credential.rotated_test", "password", func(value string) error {
+						password = value
+						return nil
+					}),
+				),
+			},
+			// The password is kept until the interval elapsed.
+			{
+				Config:   testAccCredentialResourceRotationConfig("90d"),
+				PlanOnly: true,
+			},
+			// Once the interval elapsed, a new password is generated. The
+			// plan is not empty afterwards as the interval elapses again.
+			{
+				PreConfig:          func() { time.Sleep(2 * time.Second) },
+				Config:             testAccCredentialResourceRotationConfig("1s"),
+				ExpectNonEmptyPlan: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckRotationExpires("pleasantpassword_credential.rotated_test", time.Second),
+					resource.TestCheckResourceAttrWith("pleasantpassword_credential.rotated_test", "password", func(value string) error {
+						if value == password {
+							return fmt.Errorf("expected a new password, got %q again", value)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckRotationExpires checks that the credential expires interval
+// after its last rotation.
+func testAccCheckRotationExpires(name string, interval time.Duration) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", name)
+		}
+
+		lastRotated, err := time.Parse(time.RFC3339, rs.Primary.Attributes["last_rotated"])
+		if err != nil {
+			return err
+		}
+		expires, err := time.Parse(time.RFC3339, rs.Primary.Attributes["expires"])
+		if err != nil {
+			return err
+		}
+
+		if !expires.Equal(lastRotated.Add(interval)) {
+			return fmt.Errorf("expected expires to be %s after %s, got %s", interval, lastRotated, expires)
+		}
+
+		return nil
+	}
+}
+
+func testAccCredentialResourceRotationConfig(interval string) string {
+	return fmt.Sprintf(`
+data "pleasantpassword_folder_root" "get_root_folder" {
+}
+
+resource "pleasantpassword_credential" "rotated_test" {
+	name = "acctest_rotated"
+	folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+
+	generate_password {
+	}
+
+	rotation {
+		interval = "%s"
+	}
+}
+`, interval)
+}
+
+func TestAccCredentialResourcePasswordNotInState(t *testing.T) {
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredentialResourcePasswordNotInStateConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceID("pleasantpassword_credential.hashed_test", &id),
+					resource.TestCheckNoResourceAttr("pleasantpassword_credential.hashed_test", "password"),
+					resource.TestMatchResourceAttr("pleasantpassword_credential.hashed_test", "password_sha256", regexp.MustCompile(`^[0-9a-f]{64}$`)),
+				),
+			},
+			// Refreshing does not store the password nor change its hash.
+			{
+				Config:   testAccCredentialResourcePasswordNotInStateConfig(),
+				PlanOnly: true,
+			},
+			// A password changed outside of Terraform is generated again.
+			{
+				PreConfig: func() {
+					client, ctx := testAccClient(t)
+					param := PPSClient.NewV6CredentialInputWithDefaults()
+					param.SetPassword("acctest_changed")
+					if _, err := client.DefaultAPI.PatchV6CredentialsByID(ctx, id).V6CredentialInput(*param).Execute(); err != nil {
+						t.Fatalf("unable to change the password: %s", err)
+					}
+				},
+				Config:             testAccCredentialResourcePasswordNotInStateConfig(),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccCredentialResourcePasswordNotInStateConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("pleasantpassword_credential.hashed_test", "password_sha256", func(value string) error {
+						if value == passwordHash(id, "acctest_changed") {
+							return errors.New("expected a new password to be generated")
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCredentialResourcePasswordWO(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			// Write-only attributes are supported from Terraform 1.11.
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.11.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredentialResourcePasswordWOConfig("one", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("pleasantpassword_credential.wo_test", "password"),
+					resource.TestCheckNoResourceAttr("pleasantpassword_credential.wo_test", "password_wo"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.wo_test", "store_password_in_state", "false"),
+					resource.TestCheckResourceAttr("data.pleasantpassword_credential.wo_test", "password", "acctest_passwordone"),
+				),
+			},
+			// The password is not sent while the version does not change.
+			{
+				Config: testAccCredentialResourcePasswordWOConfig("two", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pleasantpassword_credential.wo_test", "password", "acctest_passwordone"),
+				),
+			},
+			{
+				Config: testAccCredentialResourcePasswordWOConfig("two", 2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("pleasantpassword_credential.wo_test", "password"),
+					resource.TestCheckResourceAttr("data.pleasantpassword_credential.wo_test", "password", "acctest_passwordtwo"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCredentialResourceOTP(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredentialResourceOTPConfig(8),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pleasantpassword_credential.otp_test", "otp_secret", "JBSWY3DPEHPK3PXP"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.otp_test", "otp_issuer", "acctest"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.otp_test", "otp_digits", "8"),
+					resource.TestCheckResourceAttr("pleasantpassword_credential.otp_test", "otp_period", "30"),
+					resource.TestMatchResourceAttr("data.pleasantpassword_credential.otp_test", "otp_code", regexp.MustCompile(`^[0-9]{8}$`)),
+				),
+			},
+			{
+				Config: testAccCredentialResourceOTPConfig(7),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pleasantpassword_credential.otp_test", "otp_digits", "7"),
+					resource.TestMatchResourceAttr("data.pleasantpassword_credential.otp_test", "otp_code", regexp.MustCompile(`^[0-9]{7}$`)),
+				),
+			},
+			{
+				ResourceName:            "pleasantpassword_credential.otp_test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"custom_fields", "sensitive_custom_fields", "last_rotated"},
+			},
+		},
+	})
+}
+
+func testAccCredentialResourceOTPConfig(digits int) string {
+	return fmt.Sprintf(`
+data "pleasantpassword_folder_root" "get_root_folder" {
+}
+
+resource "pleasantpassword_credential" "otp_test" {
+	name = "acctest_otp"
+	folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+	password = "acctest_password"
+	custom_fields = {
+		acctest_field = "acctest_value"
+	}
+
+	otp_secret = "JBSWY3DPEHPK3PXP"
+	otp_issuer = "acctest"
+	otp_digits = %d
+}
+
+data "pleasantpassword_credential" "otp_test" {
+	credential_id = pleasantpassword_credential.otp_test.id
+}
+`, digits)
+}
+
+func testAccCredentialResourcePasswordWOConfig(password string, passwordVersion int) string {
+	return fmt.Sprintf(`
+data "pleasantpassword_folder_root" "get_root_folder" {
+}
+
+resource "pleasantpassword_credential" "wo_test" {
+	name = "acctest_write_only"
+	folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+	password_wo = "acctest_password%[1]s"
+	password_wo_version = %[2]d
+}
+
+data "pleasantpassword_credential" "wo_test" {
+	credential_id = pleasantpassword_credential.wo_test.id
+}
+`, password, passwordVersion)
+}
+
+func testAccCredentialResourcePasswordNotInStateConfig() string {
+	return `
+data "pleasantpassword_folder_root" "get_root_folder" {
+}
+
+resource "pleasantpassword_credential" "hashed_test" {
+	name = "acctest_hashed"
+	folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+	store_password_in_state = false
+
+	generate_password {
+	}
+}
+`
+}
+
+func testAccCredentialResourceGeneratePasswordConfig(keeper string) string {
+	return fmt.Sprintf(`
+data "pleasantpassword_folder_root" "get_root_folder" {
+}
+
+resource "pleasantpassword_credential" "generated_test" {
+	name = "acctest_generated"
+	folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+
+	generate_password {
+		length = 24
+		special = false
+	}
+
+	keepers = {
+		rotation = "%s"
+	}
+}
+`, keeper)
+}
+
 func testAccCredentialResourceConfig(configurableAttribute string) string {
 	return fmt.Sprintf(`
 
 data "pleasantpassword_folder_root" "get_root_folder" {
 }
 
+data "pleasantpassword_folder" "get_root_folder" {
+	folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+}
+
 resource "pleasantpassword_folder" "create_folder" {
 	name = "acctest_folder"
 	parent_id = data.pleasantpassword_folder_root.get_root_folder.id
@@ -56,6 +785,14 @@ resource "pleasantpassword_folder" "create_folder" {
 	password = "acctest_password%[1]s"
 	notes = "acctest notes"
 	username = "acctest_username1"
+	tags = ["acctest_tag%[1]s", "acctest_team"]
+	expires = "2099-01-31T00:00:00Z"
+	custom_fields = {
+		acctest_field = "acctest_value%[1]s"
+	}
+	sensitive_custom_fields = {
+		acctest_secret = "acctest_secret%[1]s"
+	}
 	
    
  }
@@ -63,3 +800,14 @@ resource "pleasantpassword_folder" "create_folder" {
 
 `, configurableAttribute)
 }
+
+func testAccCredentialImportStateIdFunc(name string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		root, ok := s.RootModule().Resources["data.pleasantpassword_folder.get_root_folder"]
+		if !ok {
+			return "", fmt.Errorf("root folder data source not found in state")
+		}
+
+		return fmt.Sprintf("%s/acctest_folder/%s", root.Primary.Attributes["name"], name), nil
+	}
+}
@@ -4,8 +4,11 @@
 package provider
 
 import (
+	"context"
+	"net/http"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
@@ -26,6 +29,42 @@ func TestAccCredentialDataSource(t *testing.T) {
 	})
 }
 
+func TestCredentialDataSourceReadsUnlistedCustomFieldsAsSensitive(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v6/rest/entries/42":
+			_, _ = w.Write([]byte(`{"Id":"42","Name":"db","CustomUserFields":{"host":"db.example.com","passphrase":"s3cr3t"}}`))
+		case "/api/v6/rest/entries/42/password":
+			_, _ = w.Write([]byte(`"password"`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	resp := testDataSourceRead(t, NewCredentialDataSource(), client, map[string]tftypes.Value{
+		"credential_id":           tftypes.NewValue(tftypes.String, "42"),
+		"plain_custom_field_keys": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{tftypes.NewValue(tftypes.String, "host")}),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var data CredentialDataSourceModel
+	resp.Diagnostics.Append(resp.State.Get(context.Background(), &data)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	if _, ok := data.CustomFields.Elements()["host"]; !ok || len(data.CustomFields.Elements()) != 1 {
+		t.Errorf("expected only the listed field to be plain, got %s", data.CustomFields)
+	}
+	if _, ok := data.SensitiveCustomFields.Elements()["passphrase"]; !ok || len(data.SensitiveCustomFields.Elements()) != 1 {
+		t.Errorf("expected the unlisted field to be sensitive, got %s", data.SensitiveCustomFields)
+	}
+}
+
 const testAccCredentialDataSourceConfig = `
 
 
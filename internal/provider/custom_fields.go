@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// customFieldsFromAPI converts the custom fields returned by the API to
+// strings, values that are not strings being kept as JSON.
+func customFieldsFromAPI(fields map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(fields))
+	for key, value := range fields {
+		switch v := value.(type) {
+		case nil:
+			values[key] = ""
+		case string:
+			values[key] = v
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				encoded = []byte(fmt.Sprint(v))
+			}
+			values[key] = string(encoded)
+		}
+	}
+
+	return values
+}
+
+// customFieldsValue returns the custom fields returned by the API as a map
+// attribute value.
+func customFieldsValue(fields map[string]interface{}) types.Map {
+	elements := map[string]attr.Value{}
+	for key, value := range customFieldsFromAPI(fields) {
+		elements[key] = types.StringValue(value)
+	}
+
+	return types.MapValueMust(types.StringType, elements)
+}
+
+// splitCustomFields splits the custom fields returned by the API in the
+// fields whose key is in sensitiveKeys and the others.
+func splitCustomFields(fields map[string]interface{}, sensitiveKeys map[string]bool) (plain types.Map, sensitive types.Map) {
+	plainElements := map[string]attr.Value{}
+	sensitiveElements := map[string]attr.Value{}
+
+	for key, value := range customFieldsFromAPI(fields) {
+		if sensitiveKeys[key] {
+			sensitiveElements[key] = types.StringValue(value)
+		} else {
+			plainElements[key] = types.StringValue(value)
+		}
+	}
+
+	return types.MapValueMust(types.StringType, plainElements), types.MapValueMust(types.StringType, sensitiveElements)
+}
+
+// unlistedKeys returns the keys of fields that are not in plainKeys. The
+// credential resource treats them as sensitive, so that custom fields it was
+// not told about, e.g. on import, are never shown in the plan output.
+func unlistedKeys(fields map[string]interface{}, plainKeys map[string]bool) map[string]bool {
+	keys := map[string]bool{}
+	for key := range fields {
+		if !plainKeys[key] {
+			keys[key] = true
+		}
+	}
+
+	return keys
+}
+
+// mapKeys returns the keys of a map attribute value.
+func mapKeys(m types.Map) map[string]bool {
+	keys := map[string]bool{}
+	for key := range m.Elements() {
+		keys[key] = true
+	}
+
+	return keys
+}
+
+// customFieldsToAPI merges the plain and sensitive custom fields to send them
+// to the API. An empty map is sent when there are none, which removes all the
+// custom fields of the credential.
+func customFieldsToAPI(ctx context.Context, plain types.Map, sensitive types.Map) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var plainValues, sensitiveValues map[string]string
+	diags.Append(plain.ElementsAs(ctx, &plainValues, false)...)
+	diags.Append(sensitive.ElementsAs(ctx, &sensitiveValues, false)...)
+
+	fields := map[string]interface{}{}
+	for key, value := range plainValues {
+		fields[key] = value
+	}
+	for key, value := range sensitiveValues {
+		if _, ok := fields[key]; ok {
+			diags.AddAttributeError(
+				path.Root("sensitive_custom_fields").AtMapKey(key),
+				"Duplicate Custom Field",
+				fmt.Sprintf("The custom field %q is set in both custom_fields and sensitive_custom_fields.", key),
+			)
+			continue
+		}
+		fields[key] = value
+	}
+
+	return fields, diags
+}
@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestSplitCustomFields(t *testing.T) {
+	fields := map[string]interface{}{
+		"environment": "prod",
+		"api_key":     "s3cr3t",
+		"port":        float64(5432),
+		"empty":       nil,
+	}
+
+	plain, sensitive := splitCustomFields(fields, map[string]bool{"api_key": true})
+
+	expectedPlain := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"environment": types.StringValue("prod"),
+		"port":        types.StringValue("5432"),
+		"empty":       types.StringValue(""),
+	})
+	if !plain.Equal(expectedPlain) {
+		t.Errorf("expected %s, got %s", expectedPlain, plain)
+	}
+
+	expectedSensitive := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"api_key": types.StringValue("s3cr3t"),
+	})
+	if !sensitive.Equal(expectedSensitive) {
+		t.Errorf("expected %s, got %s", expectedSensitive, sensitive)
+	}
+}
+
+func TestUnlistedKeys(t *testing.T) {
+	fields := map[string]interface{}{
+		"environment": "prod",
+		"passphrase":  "s3cr3t",
+	}
+
+	// Fields Terraform was not told about, e.g. on import, are sensitive.
+	_, sensitive := splitCustomFields(fields, unlistedKeys(fields, map[string]bool{}))
+	if len(sensitive.Elements()) != 2 {
+		t.Errorf("expected all the fields to be sensitive, got %s", sensitive)
+	}
+
+	plain, sensitive := splitCustomFields(fields, unlistedKeys(fields, map[string]bool{"environment": true}))
+	if len(plain.Elements()) != 1 || len(sensitive.Elements()) != 1 {
+		t.Errorf("expected only environment to be plain, got %s and %s", plain, sensitive)
+	}
+}
+
+func TestCustomFieldsToAPI(t *testing.T) {
+	plain := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"environment": types.StringValue("prod"),
+	})
+	sensitive := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"api_key": types.StringValue("s3cr3t"),
+	})
+
+	fields, diags := customFieldsToAPI(context.Background(), plain, sensitive)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if len(fields) != 2 || fields["environment"] != "prod" || fields["api_key"] != "s3cr3t" {
+		t.Errorf("unexpected custom fields %v", fields)
+	}
+
+	fields, diags = customFieldsToAPI(context.Background(), types.MapValueMust(types.StringType, nil), types.MapValueMust(types.StringType, nil))
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if fields == nil || len(fields) != 0 {
+		t.Errorf("expected an empty map to clear the custom fields, got %v", fields)
+	}
+
+	duplicate := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"environment": types.StringValue("dev"),
+	})
+	if _, diags = customFieldsToAPI(context.Background(), plain, duplicate); !diags.HasError() {
+		t.Error("expected an error for a custom field set twice")
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+// The values of the deletion_mode attribute of the credential and folder
+// resources.
+const (
+	// deletionModeDelete deletes the object permanently.
+	deletionModeDelete = "delete"
+	// deletionModeArchive moves the object to the archive of the server,
+	// from which it can be restored.
+	deletionModeArchive = "archive"
+	// deletionModeAbandon only removes the object from the state.
+	deletionModeAbandon = "abandon"
+)
+
+var deletionModes = []string{deletionModeDelete, deletionModeArchive, deletionModeAbandon}
+
+// deletionModeValue returns the deletion mode of a resource, states written
+// before deletion_mode existed deleting the object.
+func deletionModeValue(mode types.String) types.String {
+	if mode.IsNull() || mode.IsUnknown() {
+		return types.StringValue(deletionModeDelete)
+	}
+
+	return mode
+}
+
+// The actions of the DeleteAction body of the DELETE requests.
+const (
+	deleteActionDelete  = "Delete"
+	deleteActionArchive = "Archive"
+)
+
+// deleteObject deletes or archives the credential or folder at path, action
+// being one of deleteActionDelete and deleteActionArchive. The generated
+// client sends its DELETE requests without the DeleteAction body, leaving the
+// action to the default of the server, so the request goes through callAPI.
+func deleteObject(ctx context.Context, client *PPSClient.APIClient, path string, action string) (*http.Response, error) {
+	body := PPSClient.NewDeleteAction()
+	body.SetAction(action)
+	if action == deleteActionArchive {
+		body.SetComment("Archived by Terraform")
+	} else {
+		body.SetComment("Deleted by Terraform")
+	}
+
+	return callAPI(ctx, client, http.MethodDelete, path, body, nil)
+}
+
+// deleteCredential deletes a credential or moves it to the archive.
+func deleteCredential(ctx context.Context, client *PPSClient.APIClient, id string, action string) (*http.Response, error) {
+	return deleteObject(ctx, client, "/api/v6/rest/entries/"+url.PathEscape(id), action)
+}
+
+// deleteFolder deletes a folder, with its subfolders and credentials, or
+// moves them to the archive.
+func deleteFolder(ctx context.Context, client *PPSClient.APIClient, id string, action string) (*http.Response, error) {
+	return deleteObject(ctx, client, "/api/v6/rest/folders/"+url.PathEscape(id), action)
+}
+
+// deletionModeValidator checks that a string attribute holds a deletion mode.
+type deletionModeValidator struct{}
+
+var _ validator.String = deletionModeValidator{}
+
+func (v deletionModeValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be one of %s", strings.Join(deletionModes, ", "))
+}
+
+func (v deletionModeValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v deletionModeValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for _, mode := range deletionModes {
+		if req.ConfigValue.ValueString() == mode {
+			return
+		}
+	}
+
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		"Invalid Deletion Mode",
+		fmt.Sprintf("Expected one of %s, got: %q.", strings.Join(deletionModes, ", "), req.ConfigValue.ValueString()),
+	)
+}
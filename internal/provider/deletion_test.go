@@ -0,0 +1,74 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+func TestDeleteObject(t *testing.T) {
+	var requests []string
+	var actions []string
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var action PPSClient.DeleteAction
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if err := json.NewDecoder(r.Body).Decode(&action); err != nil {
+			t.Errorf("expected a DeleteAction body: %s", err)
+		}
+		actions = append(actions, action.GetAction())
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if _, err := deleteCredential(context.Background(), client, "42", deleteActionArchive); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := deleteCredential(context.Background(), client, "42", deleteActionDelete); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := deleteFolder(context.Background(), client, "43", deleteActionArchive); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := deleteFolder(context.Background(), client, "43", deleteActionDelete); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"DELETE /api/v6/rest/entries/42", "DELETE /api/v6/rest/entries/42", "DELETE /api/v6/rest/folders/43", "DELETE /api/v6/rest/folders/43"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("unexpected requests: %v", requests)
+	}
+	// The action is always sent, so that delete does not depend on the
+	// default of the server.
+	if strings.Join(actions, ",") != "Archive,Delete,Archive,Delete" {
+		t.Errorf("unexpected actions: %v", actions)
+	}
+}
+
+func TestDeletionModeValidator(t *testing.T) {
+	for value, valid := range map[string]bool{
+		deletionModeDelete:  true,
+		deletionModeArchive: true,
+		deletionModeAbandon: true,
+		"recycle":           false,
+		"":                  false,
+	} {
+		resp := &validator.StringResponse{}
+		deletionModeValidator{}.ValidateString(context.Background(), validator.StringRequest{Path: path.Root("deletion_mode"), ConfigValue: types.StringValue(value)}, resp)
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("%q: expected valid to be %t, got: %v", value, valid, resp.Diagnostics)
+		}
+	}
+
+	if mode := deletionModeValue(types.StringNull()); mode.ValueString() != deletionModeDelete {
+		t.Errorf("expected states without deletion_mode to delete, got %s", mode)
+	}
+}
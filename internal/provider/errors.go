@@ -0,0 +1,207 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// apiOperation describes an API call for the diagnostics reported when it
+// fails.
+type apiOperation struct {
+	// action is what was attempted, e.g. "create the credential".
+	action string
+	// permission is the access right the call needs, e.g. "Modify".
+	permission string
+	// object is what the permission is needed on, e.g. `folder "Team"`.
+	object string
+	// fields maps the properties of the request payload to the attributes
+	// they come from, to report validation errors on the attribute.
+	fields map[string]path.Path
+}
+
+// credentialFields maps the properties of V6CredentialInput to the attributes
+// of the credential resource.
+var credentialFields = map[string]path.Path{
+	"name":     path.Root("name"),
+	"username": path.Root("username"),
+	"password": path.Root("password"),
+	"url":      path.Root("url"),
+	"notes":    path.Root("notes"),
+	"groupid":  path.Root("folder_id"),
+	"expires":  path.Root("expires"),
+}
+
+// folderFields maps the properties of V6CredentialGroupInput to the
+// attributes of the folder resource.
+var folderFields = map[string]path.Path{
+	"name":     path.Root("name"),
+	"notes":    path.Root("notes"),
+	"parentid": path.Root("parent_id"),
+	"expires":  path.Root("expires"),
+}
+
+// apiErrorBody holds the error payloads of the PPS API: GenericError,
+// NotFoundError and the validation errors of ASP.NET.
+type apiErrorBody struct {
+	Message          string              `json:"Message"`
+	ExceptionMessage string              `json:"ExceptionMessage"`
+	ErrorMessage     string              `json:"error_message"`
+	ErrorDescription string              `json:"error_description"`
+	ModelState       map[string][]string `json:"ModelState"`
+}
+
+// parseAPIError extracts the messages of an error response. Validation errors
+// are returned by the name of the property they are about, the empty key
+// holding the messages about the request as a whole.
+func parseAPIError(body []byte) map[string][]string {
+	messages := map[string][]string{}
+
+	var payload apiErrorBody
+	if err := json.Unmarshal(body, &payload); err == nil {
+		for _, message := range []string{payload.Message, payload.ExceptionMessage, payload.ErrorMessage, payload.ErrorDescription} {
+			if message != "" {
+				messages[""] = append(messages[""], message)
+			}
+		}
+		for key, values := range payload.ModelState {
+			// Keys are prefixed with the name of the parameter, as in
+			// "credential.Name".
+			property := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+			messages[property] = append(messages[property], values...)
+		}
+
+		return messages
+	}
+
+	var text string
+	if err := json.Unmarshal(body, &text); err != nil {
+		text = string(body)
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		messages[""] = []string{text}
+	}
+
+	return messages
+}
+
+// isNotFound reports whether the server answered that the object of the
+// request does not exist. Reads only drop a resource from the state on a 404,
+// so that any other failure does not plan to create it again.
+func isNotFound(httpres *http.Response) bool {
+	return httpres != nil && httpres.StatusCode == http.StatusNotFound
+}
+
+// checkAPIResponse reports a failed API call to diags, either err or a status
+// code other than the expected ones. It returns whether the call succeeded.
+func checkAPIResponse(diags *diag.Diagnostics, op apiOperation, httpres *http.Response, err error, expected ...int) bool {
+	if err != nil {
+		addAPIError(diags, op, httpres, err)
+		return false
+	}
+
+	for _, status := range expected {
+		if httpres.StatusCode == status {
+			return true
+		}
+	}
+
+	diags.AddError(
+		"Unable to "+op.action,
+		fmt.Sprintf("The server answered with the unexpected status %s, expected %v.", httpres.Status, expected),
+	)
+
+	return false
+}
+
+// responseBodyError is an error response of the API carrying its body, as
+// the *PPSClient.GenericOpenAPIError returned by the generated client and the
+// errors returned by callAPI.
+type responseBodyError interface {
+	error
+	Body() []byte
+}
+
+// addAPIError reports the failure of an API call to diags, scoping validation
+// errors to the offending attributes and adding hints for the common causes.
+func addAPIError(diags *diag.Diagnostics, op apiOperation, httpres *http.Response, err error) {
+	summary := "Unable to " + op.action
+
+	var apierr responseBodyError
+	if httpres == nil || !errors.As(err, &apierr) {
+		diags.AddError(summary, fmt.Sprintf("The request to the Pleasant Password Server failed: %s", err))
+		return
+	}
+
+	messages := parseAPIError(apierr.Body())
+
+	// Validation errors about a known property are reported on the attribute.
+	properties := make([]string, 0, len(messages))
+	for property := range messages {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+
+	var general []string
+	for _, property := range properties {
+		attribute, ok := op.fields[property]
+		if property == "" || !ok {
+			general = append(general, messages[property]...)
+			continue
+		}
+		diags.AddAttributeError(attribute, summary, strings.Join(messages[property], "\n"))
+		delete(messages, property)
+	}
+
+	if len(general) == 0 && len(messages) < len(properties) {
+		return
+	}
+
+	detail := fmt.Sprintf("The server answered with %s", httpres.Status)
+	if len(general) > 0 {
+		detail += ": " + strings.Join(general, "\n")
+	} else {
+		detail += "."
+	}
+	if hint := apiErrorHint(op, httpres.StatusCode, strings.ToLower(strings.Join(general, " "))); hint != "" {
+		detail += "\n\n" + hint
+	}
+
+	diags.AddError(summary, detail)
+}
+
+// apiErrorHint explains the likely cause of an error response.
+func apiErrorHint(op apiOperation, status int, message string) string {
+	switch {
+	case strings.Contains(message, "comment") && strings.Contains(message, "required"):
+		return fmt.Sprintf("A comment is required by the access policy of %s, which the API cannot provide. Ask an administrator to lift the comment requirement for the user of the provider.", op.objectOrDefault())
+	case status == http.StatusUnauthorized:
+		return "The server rejected the credentials of the provider, check the username, the password and the two-factor settings."
+	case status == http.StatusForbidden || strings.Contains(message, "access denied"):
+		if op.permission != "" {
+			return fmt.Sprintf("The user of the provider needs the %s permission on %s.", op.permission, op.objectOrDefault())
+		}
+		return fmt.Sprintf("The user of the provider is not allowed to access %s.", op.objectOrDefault())
+	case status == http.StatusNotFound:
+		return fmt.Sprintf("Either %s does not exist or the user of the provider is not allowed to see it.", op.objectOrDefault())
+	}
+
+	return ""
+}
+
+func (op apiOperation) objectOrDefault() string {
+	if op.object == "" {
+		return "the object"
+	}
+
+	return op.object
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+// newTestAPIClient returns a client of a server answering every request with
+// handler.
+func newTestAPIClient(t *testing.T, handler http.HandlerFunc) *PPSClient.APIClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := PPSClient.NewConfiguration()
+	cfg.Servers = PPSClient.ServerConfigurations{{URL: server.URL}}
+	cfg.HTTPClient = server.Client()
+
+	return PPSClient.NewAPIClient(cfg)
+}
+
+func TestAddAPIErrorScopesValidationErrors(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"Message":"The request is invalid.","ModelState":{"credential.Name":["The Name field is required."],"credential.Other":["Unknown property."]}}`))
+	})
+
+	httpres, err := client.DefaultAPI.PatchV6CredentialsByID(context.Background(), "42").V6CredentialInput(*PPSClient.NewV6CredentialInput()).Execute()
+
+	var diags diag.Diagnostics
+	if checkAPIResponse(&diags, apiOperation{action: "update the credential", fields: credentialFields}, httpres, err, 204) {
+		t.Fatal("expected the call to be reported as failed")
+	}
+
+	if diags.ErrorsCount() != 2 {
+		t.Fatalf("expected 2 errors, got: %v", diags)
+	}
+
+	attributeError, ok := diags[0].(diag.DiagnosticWithPath)
+	if !ok || !attributeError.Path().Equal(path.Root("name")) || attributeError.Detail() != "The Name field is required." {
+		t.Errorf("expected an error on the name attribute, got: %v", diags[0])
+	}
+
+	if detail := diags[1].Detail(); !strings.Contains(detail, "400") || !strings.Contains(detail, "The request is invalid.") || !strings.Contains(detail, "Unknown property.") {
+		t.Errorf("unexpected detail: %s", detail)
+	}
+}
+
+func TestAddAPIErrorHintsMissingPermission(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"Message":"Access denied."}`))
+	})
+
+	httpres, err := client.DefaultAPI.DeleteV6FoldersByID(context.Background(), "42").Execute()
+
+	var diags diag.Diagnostics
+	checkAPIResponse(&diags, apiOperation{action: "delete the folder", permission: "Delete", object: `folder "42"`}, httpres, err, 204)
+
+	if diags.ErrorsCount() != 1 {
+		t.Fatalf("expected 1 error, got: %v", diags)
+	}
+	if summary := diags[0].Summary(); summary != "Unable to delete the folder" {
+		t.Errorf("unexpected summary: %s", summary)
+	}
+	if detail := diags[0].Detail(); !strings.Contains(detail, "Access denied.") || !strings.Contains(detail, `the Delete permission on folder "42"`) {
+		t.Errorf("unexpected detail: %s", detail)
+	}
+}
+
+func TestParseAPIError(t *testing.T) {
+	cases := map[string]string{
+		`{"error_message":"Credential not found"}`: "Credential not found",
+		`"Comment required"`:                       "Comment required",
+		"Service Unavailable\n":                    "Service Unavailable",
+	}
+
+	for body, expected := range cases {
+		messages := parseAPIError([]byte(body))
+		if len(messages[""]) != 1 || messages[""][0] != expected {
+			t.Errorf("%s: expected %q, got %v", body, expected, messages)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	PPSClient "github.com/theochita/go-pleasant-password"
@@ -135,6 +137,22 @@ func (d *FolderDataSource) Schema(ctx context.Context, req datasource.SchemaRequ
 							MarkdownDescription: "The expiration date of the credential",
 							Computed:            true,
 						},
+						"custom_fields": schema.MapAttribute{
+							MarkdownDescription: "The custom user fields of the credential, all marked as sensitive",
+							ElementType:         types.StringType,
+							Sensitive:           true,
+							Computed:            true,
+						},
+						"custom_application_fields": schema.MapAttribute{
+							MarkdownDescription: "The custom application fields of the credential",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+						"otp_code": schema.StringAttribute{
+							MarkdownDescription: "The current TOTP code of the credential, computed from the TOTP properties of the credential, or null when it has none or the user may not view it. It is also null, with a warning, when the TOTP settings cannot be used",
+							Sensitive:           true,
+							Computed:            true,
+						},
 
 						"tags": schema.ListNestedAttribute{
 							Computed: true,
@@ -238,6 +256,22 @@ func (d *FolderDataSource) Schema(ctx context.Context, req datasource.SchemaRequ
 										MarkdownDescription: "The expiration date of the credential",
 										Computed:            true,
 									},
+									"custom_fields": schema.MapAttribute{
+										MarkdownDescription: "The custom user fields of the credential, all marked as sensitive",
+										ElementType:         types.StringType,
+										Sensitive:           true,
+										Computed:            true,
+									},
+									"custom_application_fields": schema.MapAttribute{
+										MarkdownDescription: "The custom application fields of the credential",
+										ElementType:         types.StringType,
+										Computed:            true,
+									},
+									"otp_code": schema.StringAttribute{
+										MarkdownDescription: "The current TOTP code of the credential, computed from the TOTP properties of the credential, or null when it has none or the user may not view it. It is also null, with a warning, when the TOTP settings cannot be used",
+										Sensitive:           true,
+										Computed:            true,
+									},
 
 									"tags": schema.ListNestedAttribute{
 										Computed: true,
@@ -293,9 +327,11 @@ func (d *FolderDataSource) fetchTags(res []PPSClient.V6TagResult) []models.Tag {
 
 }
 
-func (d *FolderDataSource) fetchCredentials(res []PPSClient.V6CredentialResult) []models.Credential {
+// fetchCredentials returns the credentials of a folder, p being the path of
+// the list they are stored in.
+func (d *FolderDataSource) fetchCredentials(res []PPSClient.V6CredentialResult, p path.Path, diags *diag.Diagnostics) []models.Credential {
 	var creds = []models.Credential{}
-	for _, v := range res {
+	for i, v := range res {
 		cred := models.Credential{}
 		cred.Id = types.StringValue(v.GetId())
 		cred.Name = types.StringValue(v.GetName())
@@ -303,9 +339,12 @@ func (d *FolderDataSource) fetchCredentials(res []PPSClient.V6CredentialResult)
 		cred.Url = types.StringValue(v.GetUrl())
 		cred.Notes = types.StringValue(v.GetNotes())
 		cred.Folderid = types.StringValue(v.GetGroupId())
-		cred.Created = types.StringValue("Not implemented")
-		cred.Modified = types.StringValue("Not implemented")
-		cred.Expires = types.StringValue(v.GetExpires())
+		cred.Created = timestampValue(v.Created)
+		cred.Modified = timestampValue(v.Modified)
+		cred.Expires = expiresValue(v.Expires, types.StringNull())
+		cred.OTPCode = readListedOTPCode(&v, p.AtListIndex(i).AtName("otp_code"), diags)
+		cred.CustomFields = customFieldsValue(v.CustomUserFields)
+		cred.CustomApplicationFields = customFieldsValue(v.CustomApplicationFields)
 
 		cred.Tags = d.fetchTags(v.Tags)
 
@@ -317,22 +356,22 @@ func (d *FolderDataSource) fetchCredentials(res []PPSClient.V6CredentialResult)
 
 }
 
-func (d *FolderDataSource) fetchChildren(res []PPSClient.V6CredentialGroupOutput) []models.CredentialGroup {
+func (d *FolderDataSource) fetchChildren(res []PPSClient.V6CredentialGroupOutput, diags *diag.Diagnostics) []models.CredentialGroup {
 
 	var children = []models.CredentialGroup{}
-	for _, v := range res {
+	for i, v := range res {
 		child := models.CredentialGroup{}
 		child.Id = types.StringValue(v.GetId())
 		child.Name = types.StringValue(v.GetName())
 		child.ParentId = types.StringValue(v.GetParentId())
 		child.Notes = types.StringValue(v.GetNotes())
-		child.Created = types.StringValue("Not implemented")
-		child.Modified = types.StringValue("Not implemented")
-		child.Expires = types.StringValue(v.GetExpires())
+		child.Created = timestampValue(v.Created)
+		child.Modified = timestampValue(v.Modified)
+		child.Expires = expiresValue(v.Expires, types.StringNull())
 
 		child.Tags = d.fetchTags(v.GetTags())
 
-		child.Credentials = d.fetchCredentials(v.GetCredentials())
+		child.Credentials = d.fetchCredentials(v.GetCredentials(), path.Root("children").AtListIndex(i).AtName("credentials"), diags)
 
 		children = append(children, child)
 
@@ -343,6 +382,10 @@ func (d *FolderDataSource) fetchChildren(res []PPSClient.V6CredentialGroupOutput
 }
 
 func (d *FolderDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if failWhileConfigUnknown(d.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data FolderDataSourceModel
 
 	// Read Terraform configuration data into the model
@@ -357,12 +400,7 @@ func (d *FolderDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	client := d.client
 
 	res, httpres, err := client.DefaultAPI.GetV6FoldersByID(*d.ctx, folderid).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
-		return
-	}
-	if httpres.StatusCode != 200 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "read the folder", permission: "View", object: fmt.Sprintf("folder %q", folderid)}, httpres, err, 200) {
 		return
 	}
 
@@ -374,18 +412,19 @@ func (d *FolderDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	data.Name = types.StringValue(res.GetName())
 	data.ParentID = types.StringValue(res.GetParentId())
 	data.Notes = types.StringValue(res.GetNotes())
-	data.Created = types.StringValue("Not implemented")
-	data.Modified = types.StringValue("Not implemented")
-	data.Expires = types.StringValue(res.GetExpires())
+	data.Created = timestampValue(res.Created)
+	data.Modified = timestampValue(res.Modified)
+	data.Expires = expiresValue(res.Expires, types.StringNull())
 
 	data.Tags = d.fetchTags(res.GetTags())
 
-	data.Credentials = d.fetchCredentials(res.GetCredentials())
-	data.Children = d.fetchChildren(res.GetChildren())
+	data.Credentials = d.fetchCredentials(res.GetCredentials(), path.Root("credentials"), &resp.Diagnostics)
+	data.Children = d.fetchChildren(res.GetChildren(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	// Write logs using the tflog package
-	// Documentation: https://terraform.io/plugin/log
-	tflog.Trace(ctx, "read a data source")
+	tflog.Trace(ctx, "Read folder", map[string]interface{}{"id": data.Id.ValueString()})
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
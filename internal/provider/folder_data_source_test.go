@@ -6,7 +6,10 @@ package provider
 import (
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	PPSClient "github.com/theochita/go-pleasant-password"
 )
 
 func TestAccFolderDataSource(t *testing.T) {
@@ -39,3 +42,31 @@ data "pleasantpassword_folder" "fetch_root_folder_test" {
   
 }
 `
+
+func TestFolderDataSourceFetchCredentialsReadsOTP(t *testing.T) {
+	d := &FolderDataSource{}
+
+	var diags diag.Diagnostics
+	creds := d.fetchCredentials([]PPSClient.V6CredentialResult{
+		{
+			Id:               PPSClient.PtrString("1"),
+			CustomUserFields: map[string]interface{}{"env": "prod"},
+			TOTPSecret:       PPSClient.PtrString("JBSWY3DPEHPK3PXP"),
+		},
+		{
+			Id:         PPSClient.PtrString("2"),
+			TOTPSecret: PPSClient.PtrString("not base32!"),
+		},
+	}, path.Root("credentials"), &diags)
+
+	if len(creds) != 2 || len(creds[0].OTPCode.ValueString()) != totpDefaultDigits {
+		t.Fatalf("expected the OTP code of the first credential, got %+v", creds)
+	}
+	// Invalid TOTP settings of one credential do not fail the whole folder.
+	if !creds[1].OTPCode.IsNull() {
+		t.Errorf("expected a null OTP code for the second credential, got %s", creds[1].OTPCode)
+	}
+	if diags.HasError() || diags.WarningsCount() != 1 || !diags[0].(diag.DiagnosticWithPath).Path().Equal(path.Root("credentials").AtListIndex(1).AtName("otp_code")) {
+		t.Errorf("expected a warning on the OTP code of the second credential, got %v", diags)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+var guidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// isGUID reports whether id looks like a Pleasant Password Server identifier.
+func isGUID(id string) bool {
+	return guidRegexp.MatchString(id)
+}
+
+// splitFolderPath splits a slash separated path such as "Root/Infra/Prod" into
+// its segments, ignoring leading and trailing slashes. A slash in a name is
+// escaped as \/ and a backslash as \\.
+func splitFolderPath(folderPath string) []string {
+	var segments []string
+	var current strings.Builder
+
+	for i := 0; i < len(folderPath); i++ {
+		switch c := folderPath[i]; {
+		case c == '\\' && i+1 < len(folderPath):
+			i++
+			current.WriteByte(folderPath[i])
+		case c == '/':
+			segments = append(segments, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	segments = append(segments, current.String())
+
+	for len(segments) > 0 && segments[0] == "" {
+		segments = segments[1:]
+	}
+	for len(segments) > 0 && segments[len(segments)-1] == "" {
+		segments = segments[:len(segments)-1]
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+
+	return segments
+}
+
+// joinFolderPath is the inverse of splitFolderPath.
+func joinFolderPath(segments []string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = strings.NewReplacer(`\`, `\\`, "/", `\/`).Replace(segment)
+	}
+
+	return strings.Join(escaped, "/")
+}
+
+// resolveFolderPath walks the folder tree starting at the root folder and
+// returns the folder addressed by segments. The first segment must be the name
+// of the root folder.
+func resolveFolderPath(ctx context.Context, client *PPSClient.APIClient, segments []string) (*PPSClient.V6CredentialGroupOutput, error) {
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("folder path is empty")
+	}
+
+	rootres, _, err := client.DefaultAPI.GetV6FoldersRoot(ctx).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the root folder: %w", err)
+	}
+
+	rootid, err := strconv.Unquote(rootres)
+	if err != nil {
+		rootid = rootres
+	}
+
+	folder, _, err := client.DefaultAPI.GetV6FoldersByID(ctx, rootid).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read folder %s: %w", rootid, err)
+	}
+
+	if folder.GetName() != segments[0] {
+		return nil, fmt.Errorf("the path must start with the root folder %q, got %q", folder.GetName(), segments[0])
+	}
+
+	for i, name := range segments[1:] {
+		var matches []string
+		for _, child := range folder.GetChildren() {
+			if child.GetName() == name {
+				matches = append(matches, child.GetId())
+			}
+		}
+
+		current := joinFolderPath(segments[:i+2])
+
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("folder %q does not exist", current)
+		case 1:
+		default:
+			return nil, fmt.Errorf("folder path %q is ambiguous, it matches the folders %s", current, strings.Join(matches, ", "))
+		}
+
+		folder, _, err = client.DefaultAPI.GetV6FoldersByID(ctx, matches[0]).Execute()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read folder %s: %w", matches[0], err)
+		}
+	}
+
+	return folder, nil
+}
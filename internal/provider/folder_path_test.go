@@ -0,0 +1,34 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitFolderPath(t *testing.T) {
+	cases := map[string][]string{
+		"Root/Infra/Prod":          {"Root", "Infra", "Prod"},
+		"/Root/Infra/":             {"Root", "Infra"},
+		`Root/CI\/CD/deploy`:       {"Root", "CI/CD", "deploy"},
+		`Root/back\\slash/a\/b\/c`: {"Root", `back\slash`, "a/b/c"},
+		"/":                        nil,
+		"":                         nil,
+	}
+
+	for folderPath, expected := range cases {
+		segments := splitFolderPath(folderPath)
+		if !reflect.DeepEqual(segments, expected) {
+			t.Errorf("%q: expected %q, got %q", folderPath, expected, segments)
+		}
+		if expected != nil && splitFolderPath(joinFolderPath(segments)) == nil {
+			t.Errorf("%q: unable to split the joined path %q", folderPath, joinFolderPath(segments))
+		}
+	}
+
+	if joined := joinFolderPath([]string{"Root", "CI/CD", `a\b`}); joined != `Root/CI\/CD/a\\b` {
+		t.Errorf("unexpected joined path %q", joined)
+	}
+}
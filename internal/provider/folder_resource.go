@@ -6,13 +6,19 @@ package provider
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	PPSClient "github.com/theochita/go-pleasant-password"
 )
 
@@ -32,10 +38,14 @@ type FolderResource struct {
 
 // ExampleResourceModel describes the resource data model.
 type FolderResourceModel struct {
-	Id       types.String `tfsdk:"id"`
-	Name     types.String `tfsdk:"name"`
-	ParentID types.String `tfsdk:"parent_id"`
-	Notes    types.String `tfsdk:"notes"`
+	Id           types.String `tfsdk:"id"`
+	Name         types.String `tfsdk:"name"`
+	ParentID     types.String `tfsdk:"parent_id"`
+	Notes        types.String `tfsdk:"notes"`
+	Created      types.String `tfsdk:"created"`
+	Modified     types.String `tfsdk:"modified"`
+	Expires      types.String `tfsdk:"expires"`
+	DeletionMode types.String `tfsdk:"deletion_mode"`
 }
 
 func (r *FolderResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -69,6 +79,37 @@ func (r *FolderResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				Optional:            true,
 				Computed:            true,
 			},
+			"created": schema.StringAttribute{
+				MarkdownDescription: "The creation timestamp of the folder, in RFC 3339 format.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"modified": schema.StringAttribute{
+				MarkdownDescription: "The last modification timestamp of the folder, in RFC 3339 format.",
+				Computed:            true,
+			},
+			"expires": schema.StringAttribute{
+				MarkdownDescription: "The expiration date of the folder, in RFC 3339 format, e.g. `2030-01-31T00:00:00Z`. Removing it from the configuration keeps the current expiration date.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					rfc3339Validator{},
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"deletion_mode": schema.StringAttribute{
+				MarkdownDescription: "What to do with the folder, its subfolders and its credentials when it is destroyed: `delete` deletes them permanently, `archive` moves them to the archive of the server, from which they can be restored, and `abandon` only removes the folder from the state. Defaults to `delete`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(deletionModeDelete),
+				Validators: []validator.String{
+					deletionModeValidator{},
+				},
+			},
 		},
 	}
 }
@@ -95,6 +136,19 @@ func (r *FolderResource) Configure(ctx context.Context, req resource.ConfigureRe
 
 }
 
+// readComputed sets the attributes computed by the server after the folder
+// has been created or updated.
+func (r *FolderResource) readComputed(data *FolderResourceModel, diags *diag.Diagnostics) {
+	res, httpres, err := r.client.DefaultAPI.GetV6FoldersByID(*r.ctx, data.Id.ValueString()).Execute()
+	if !checkAPIResponse(diags, apiOperation{action: "read the folder", permission: "View", object: fmt.Sprintf("folder %q", data.Id.ValueString())}, httpres, err, 200) {
+		return
+	}
+
+	data.Created = timestampValue(res.Created)
+	data.Modified = timestampValue(res.Modified)
+	data.Expires = expiresValue(res.Expires, data.Expires)
+}
+
 func (r *FolderResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data FolderResourceModel
 
@@ -109,15 +163,11 @@ func (r *FolderResource) Create(ctx context.Context, req resource.CreateRequest,
 	param.Name = data.Name.ValueStringPointer()
 	param.Notes = data.Notes.ValueStringPointer()
 	param.ParentId = data.ParentID.ValueStringPointer()
+	param.Expires = expiresToAPI(data.Expires)
 
 	res, httpres, err := r.client.DefaultAPI.PostV6Folders(*r.ctx).V6CredentialGroupInput(*param).Execute()
 
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
-		return
-	}
-	if httpres.StatusCode != 200 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "create the folder", permission: "Add", object: fmt.Sprintf("folder %q", data.ParentID.ValueString()), fields: folderFields}, httpres, err, 200) {
 		return
 	}
 
@@ -134,12 +184,17 @@ func (r *FolderResource) Create(ctx context.Context, req resource.CreateRequest,
 	data.Name = types.StringValue(param.GetName())
 	data.Notes = types.StringValue(param.GetNotes())
 	data.ParentID = types.StringValue(param.GetParentId())
+	r.readComputed(&data, &resp.Diagnostics)
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *FolderResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if skipReadWhileConfigUnknown(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data FolderResourceModel
 
 	// Read Terraform prior state data into the model
@@ -151,12 +206,12 @@ func (r *FolderResource) Read(ctx context.Context, req resource.ReadRequest, res
 
 	res, httpres, err := r.client.DefaultAPI.GetV6FoldersByID(*r.ctx, data.Id.ValueString()).Execute()
 
-	if err != nil {
+	if isNotFound(httpres) {
+		tflog.Warn(ctx, "Folder not found, removing it from the state", map[string]interface{}{"id": data.Id.ValueString()})
 		resp.State.RemoveResource(ctx)
 		return
 	}
-	if httpres.StatusCode != 200 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "read the folder", permission: "View", object: fmt.Sprintf("folder %q", data.Id.ValueString())}, httpres, err, 200) {
 		return
 	}
 
@@ -167,6 +222,10 @@ func (r *FolderResource) Read(ctx context.Context, req resource.ReadRequest, res
 	data.Name = types.StringValue(res.GetName())
 	data.Notes = types.StringValue(res.GetNotes())
 	data.ParentID = types.StringValue(res.GetParentId())
+	data.Created = timestampValue(res.Created)
+	data.Modified = timestampValue(res.Modified)
+	data.Expires = expiresValue(res.Expires, data.Expires)
+	data.DeletionMode = deletionModeValue(data.DeletionMode)
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -186,15 +245,11 @@ func (r *FolderResource) Update(ctx context.Context, req resource.UpdateRequest,
 	param.Name = data.Name.ValueStringPointer()
 	param.Notes = data.Notes.ValueStringPointer()
 	param.ParentId = data.ParentID.ValueStringPointer()
+	param.Expires = expiresToAPI(data.Expires)
 
 	httpres, err := r.client.DefaultAPI.PatchV6FoldersByID(*r.ctx, data.Id.ValueString()).V6CredentialGroupInput(*param).Execute()
 
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
-		return
-	}
-	if httpres.StatusCode != 204 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "update the folder", permission: "Modify", object: fmt.Sprintf("folder %q", data.Id.ValueString()), fields: folderFields}, httpres, err, 204) {
 		return
 	}
 
@@ -202,6 +257,8 @@ func (r *FolderResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	r.readComputed(&data, &resp.Diagnostics)
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -218,14 +275,26 @@ func (r *FolderResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	httpres, err := r.client.DefaultAPI.DeleteV6FoldersByID(*r.ctx, data.Id.ValueString()).Execute()
+	var httpres *http.Response
+	var err error
+	op := apiOperation{action: "delete the folder", permission: "Delete", object: fmt.Sprintf("folder %q", data.Id.ValueString())}
 
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
+	switch deletionModeValue(data.DeletionMode).ValueString() {
+	case deletionModeAbandon:
+		tflog.Warn(ctx, "Abandoning folder, it is kept on the server", map[string]interface{}{"id": data.Id.ValueString()})
+		return
+	case deletionModeArchive:
+		op = apiOperation{action: "archive the folder", permission: "Archive", object: fmt.Sprintf("folder %q", data.Id.ValueString())}
+		httpres, err = deleteFolder(*r.ctx, r.client, data.Id.ValueString(), deleteActionArchive)
+	default:
+		httpres, err = deleteFolder(*r.ctx, r.client, data.Id.ValueString(), deleteActionDelete)
+	}
+
+	// The folder was already deleted outside of Terraform.
+	if isNotFound(httpres) {
 		return
 	}
-	if httpres.StatusCode != 204 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	if !checkAPIResponse(&resp.Diagnostics, op, httpres, err, 200, 204) {
 		return
 	}
 
@@ -236,5 +305,27 @@ func (r *FolderResource) Delete(ctx context.Context, req resource.DeleteRequest,
 }
 
 func (r *FolderResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// not implemented
+	// Import by folder ID
+	if isGUID(req.ID) {
+		resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+		return
+	}
+
+	// Import by folder path
+	segments := splitFolderPath(req.ID)
+	if len(segments) == 0 {
+		resp.Diagnostics.AddError(
+			"Invalid Import Identifier",
+			fmt.Sprintf("Expected a folder ID or a path such as Root/Folder, got: %q", req.ID),
+		)
+		return
+	}
+
+	folder, err := resolveFolderPath(*r.ctx, r.client, segments)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to resolve the folder path", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), folder.GetId())...)
 }
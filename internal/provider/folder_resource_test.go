@@ -5,9 +5,12 @@ package provider
 
 import (
 	"fmt"
+	"net/http"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 func TestAccFolderResource(t *testing.T) {
@@ -20,14 +23,31 @@ func TestAccFolderResource(t *testing.T) {
 				Config: testAccFolderResourceConfig("one"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("pleasantpassword_folder.create_folder", "name", "acctest_folderone"),
+					resource.TestCheckResourceAttr("pleasantpassword_folder.create_folder", "expires", "2099-01-31T00:00:00Z"),
+					resource.TestMatchResourceAttr("pleasantpassword_folder.create_folder", "created", testAccRFC3339Regexp),
 				),
 			},
+			// ImportState testing
+			{
+				ResourceName:      "pleasantpassword_folder.create_folder",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// ImportState by folder path testing
+			{
+				ResourceName:      "pleasantpassword_folder.create_folder",
+				ImportState:       true,
+				ImportStateIdFunc: testAccFolderImportStateIdFunc("acctest_folderone"),
+				ImportStateVerify: true,
+			},
 
 			// Update and Read testing
 			{
 				Config: testAccFolderResourceConfig("two"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("pleasantpassword_folder.create_folder", "name", "acctest_foldertwo"),
+					resource.TestCheckResourceAttr("pleasantpassword_folder.create_folder", "expires", "2099-01-31T00:00:00Z"),
+					resource.TestMatchResourceAttr("pleasantpassword_folder.create_folder", "created", testAccRFC3339Regexp),
 				),
 			},
 			// Delete testing automatically occurs in TestCase
@@ -35,6 +55,75 @@ func TestAccFolderResource(t *testing.T) {
 	})
 }
 
+func TestFolderResourceReadRemovesOnlyMissingFolder(t *testing.T) {
+	status := http.StatusNotFound
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"Message":"An error has occurred."}`))
+	})
+
+	resp := testResourceRead(t, NewFolderResource(), client, "00000000-0000-0000-0000-000000000042")
+	if resp.Diagnostics.HasError() || !resp.State.Raw.IsNull() {
+		t.Fatalf("expected a missing folder to be removed from the state, got: %v", resp.Diagnostics)
+	}
+
+	status = http.StatusInternalServerError
+	resp = testResourceRead(t, NewFolderResource(), client, "00000000-0000-0000-0000-000000000042")
+	if !resp.Diagnostics.HasError() || resp.State.Raw.IsNull() {
+		t.Fatalf("expected a server error to be reported and the folder to be kept, got: %v", resp.Diagnostics)
+	}
+}
+
+func TestAccFolderResourceDeletedOutsideTerraform(t *testing.T) {
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFolderResourceConfig("one"),
+				Check:  testAccResourceID("pleasantpassword_folder.create_folder", &id),
+			},
+			// The folder is planned to be created again
+			{
+				PreConfig: func() {
+					client, ctx := testAccClient(t)
+					if _, err := client.DefaultAPI.DeleteV6FoldersByID(ctx, id).Execute(); err != nil {
+						t.Fatalf("unable to delete the folder: %s", err)
+					}
+				},
+				Config:             testAccFolderResourceConfig("one"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccFolderResourceReadFailureKeepsState(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFolderResourceConfig("one"),
+			},
+			// Refreshing fails with the wrong password
+			{
+				Config:      testAccProviderWrongPasswordConfig + testAccFolderResourceConfig("one"),
+				ExpectError: regexp.MustCompile(`Unable to read the`),
+			},
+			// The folder is still in the state, nothing is planned
+			{
+				Config:   testAccFolderResourceConfig("one"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
 func testAccFolderResourceConfig(configurableAttribute string) string {
 	return fmt.Sprintf(`
 
@@ -45,11 +134,27 @@ func testAccFolderResourceConfig(configurableAttribute string) string {
 data "pleasantpassword_folder_root" "get_root_folder" {
 }
 
+data "pleasantpassword_folder" "get_root_folder" {
+	folder_id = data.pleasantpassword_folder_root.get_root_folder.id
+}
+
 resource "pleasantpassword_folder" "create_folder" {
 	name = "acctest_folder%s"
 	parent_id = data.pleasantpassword_folder_root.get_root_folder.id
 	notes = "testnotes"
+	expires = "2099-01-31T00:00:00Z"
    
  }
 `, configurableAttribute)
 }
+
+func testAccFolderImportStateIdFunc(name string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		root, ok := s.RootModule().Resources["data.pleasantpassword_folder.get_root_folder"]
+		if !ok {
+			return "", fmt.Errorf("root folder data source not found in state")
+		}
+
+		return fmt.Sprintf("%s/%s", root.Primary.Attributes["name"], name), nil
+	}
+}
@@ -72,6 +72,10 @@ func (d *FolderRootDataSource) Configure(ctx context.Context, req datasource.Con
 }
 
 func (d *FolderRootDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if failWhileConfigUnknown(d.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data FolderRootDataSourceModel
 
 	// Read Terraform configuration data into the model
@@ -84,12 +88,7 @@ func (d *FolderRootDataSource) Read(ctx context.Context, req datasource.ReadRequ
 	client := d.client
 
 	res, httpres, err := client.DefaultAPI.GetV6FoldersRoot(*d.ctx).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
-		return
-	}
-	if httpres.StatusCode != 200 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "read the root folder", permission: "View", object: "the root folder"}, httpres, err, 200) {
 		return
 	}
 
@@ -103,9 +102,7 @@ func (d *FolderRootDataSource) Read(ctx context.Context, req datasource.ReadRequ
 	}
 	data.ID = types.StringValue(sanityid)
 
-	// Write logs using the tflog package
-	// Documentation: https://terraform.io/plugin/log
-	tflog.Trace(ctx, "read a data source")
+	tflog.Trace(ctx, "Read root folder", map[string]interface{}{"id": data.ID.ValueString()})
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/time/rate"
+)
+
+// limitTransport throttles the requests of a provider instance. Every
+// resource and data source shares the client of the instance, so the limits
+// apply to all of them together.
+type limitTransport struct {
+	next http.RoundTripper
+	// slots holds a token per request in flight, it is nil when the number
+	// of concurrent requests is not limited.
+	slots chan struct{}
+	// limiter is nil when the request rate is not limited.
+	limiter *rate.Limiter
+}
+
+// limitSettings are the throttling attributes of the provider configuration.
+type limitSettings struct {
+	maxConcurrentRequests types.Int64
+	requestsPerSecond     types.Float64
+}
+
+// newLimitTransport validates settings and returns a limitTransport sending
+// requests through next. Unset or zero limits are not enforced.
+func newLimitTransport(next http.RoundTripper, settings limitSettings) (*limitTransport, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	t := &limitTransport{next: next}
+
+	if concurrency := settings.maxConcurrentRequests.ValueInt64(); concurrency < 0 {
+		diags.AddAttributeError(path.Root("max_concurrent_requests"), "Invalid Concurrency Limit", "max_concurrent_requests must be zero or greater.")
+	} else if concurrency > 0 {
+		t.slots = make(chan struct{}, concurrency)
+	}
+
+	if rps := settings.requestsPerSecond.ValueFloat64(); rps < 0 || math.IsNaN(rps) || math.IsInf(rps, 0) {
+		diags.AddAttributeError(path.Root("requests_per_second"), "Invalid Rate Limit", "requests_per_second must be a finite number, zero or greater.")
+	} else if rps > 0 {
+		// The bucket holds a second worth of requests, so that short bursts
+		// are not delayed while the average rate is kept.
+		t.limiter = rate.NewLimiter(rate.Limit(rps), int(math.Max(1, math.Ceil(rps))))
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return t, diags
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	release := func() {}
+	if t.slots != nil {
+		var once sync.Once
+		release = func() { once.Do(func() { <-t.slots }) }
+	}
+
+	if t.limiter != nil {
+		if err := t.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// The request is in flight until its response has been read.
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+// releaseOnClose calls release once the body it wraps is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
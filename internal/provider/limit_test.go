@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestLimitTransportBoundsConcurrentRequests(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}))
+	defer server.Close()
+
+	transport, diags := newLimitTransport(http.DefaultTransport, limitSettings{
+		maxConcurrentRequests: types.Int64Value(2),
+		requestsPerSecond:     types.Float64Null(),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	client := &http.Client{Transport: transport}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if p := atomic.LoadInt32(&peak); p > 2 {
+		t.Fatalf("expected at most 2 requests in flight, got %d", p)
+	}
+}
+
+func TestLimitTransportLimitsRequestRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	transport, diags := newLimitTransport(http.DefaultTransport, limitSettings{
+		maxConcurrentRequests: types.Int64Null(),
+		requestsPerSecond:     types.Float64Value(20),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	client := &http.Client{Transport: transport}
+
+	// The first 20 requests use the burst, the next 10 take half a second.
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Fatalf("expected the requests to be throttled, took %s", elapsed)
+	}
+}
+
+func TestLimitTransportRejectsNegativeLimits(t *testing.T) {
+	_, diags := newLimitTransport(http.DefaultTransport, limitSettings{
+		maxConcurrentRequests: types.Int64Value(-1),
+		requestsPerSecond:     types.Float64Value(-1),
+	})
+	if diags.ErrorsCount() != 2 {
+		t.Fatalf("expected 2 errors, got: %v", diags)
+	}
+}
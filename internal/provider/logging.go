@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// apiLogSubsystem is the tflog subsystem of the API requests, its level can be
+// set on its own with the TF_LOG_PROVIDER_PLEASANTPASSWORD_API environment
+// variable.
+const apiLogSubsystem = "api"
+
+// requestIDHeader carries the identifier of a request, so that it can be
+// found in the logs of a reverse proxy or of the server.
+const requestIDHeader = "X-Request-ID"
+
+// maxLoggedBodySize is the number of bytes of a body logged at TRACE level.
+const maxLoggedBodySize = 16 * 1024
+
+// secretFieldKeys are the log fields whose values are never written out.
+var secretFieldKeys = []string{
+	"authorization",
+	"password",
+	"access_token",
+	"refresh_token",
+	"otp",
+	"x-pleasant-otp",
+	"totp_secret",
+}
+
+// secretBodyValues match the secrets sent or received in request bodies, JSON
+// properties as well as form fields of the token endpoint. The custom user
+// fields of a credential are masked as a whole, since any of them may be
+// listed in sensitive_custom_fields, including when the body is truncated.
+var secretBodyValues = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)"(password|access_token|refresh_token|totpsecret|secret|filedata|otp)"\s*:\s*"(\\.|[^"\\])*"`),
+	regexp.MustCompile(`(?i)"customuserfields"\s*:\s*\{("(\\.|[^"\\])*"?|[^"}])*\}?`),
+	regexp.MustCompile(`(?i)\b(password|otp)=[^&\s]*`),
+}
+
+// newLoggingContext returns ctx set up to log API requests through the api
+// subsystem with secrets masked.
+func newLoggingContext(ctx context.Context) context.Context {
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, secretFieldKeys...)
+	ctx = tflog.NewSubsystem(ctx, apiLogSubsystem)
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, apiLogSubsystem, secretFieldKeys...)
+	ctx = tflog.SubsystemMaskAllFieldValuesRegexes(ctx, apiLogSubsystem, secretBodyValues...)
+
+	return ctx
+}
+
+// loggingTransport logs every request sent to the server at DEBUG level, and
+// their headers and bodies at TRACE level.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	req = req.Clone(ctx)
+	requestID := newRequestID()
+	req.Header.Set(requestIDHeader, requestID)
+
+	ctx = tflog.SubsystemSetField(ctx, apiLogSubsystem, "request_id", requestID)
+	ctx = tflog.SubsystemSetField(ctx, apiLogSubsystem, "method", req.Method)
+	ctx = tflog.SubsystemSetField(ctx, apiLogSubsystem, "path", req.URL.Path)
+
+	fields := headerFields(req.Header)
+	if req.Body != nil && req.Body != http.NoBody {
+		var body string
+		body, req.Body = peekBody(req.Body)
+		fields["request_body"] = body
+	}
+
+	tflog.SubsystemDebug(ctx, apiLogSubsystem, "Sending API request")
+	tflog.SubsystemTrace(ctx, apiLogSubsystem, "API request details", fields)
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	ctx = tflog.SubsystemSetField(ctx, apiLogSubsystem, "duration_ms", time.Since(start).Milliseconds())
+
+	if err != nil {
+		tflog.SubsystemDebug(ctx, apiLogSubsystem, "API request failed", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	tflog.SubsystemDebug(ctx, apiLogSubsystem, "Received API response", map[string]interface{}{"status": resp.StatusCode})
+
+	fields = headerFields(resp.Header)
+	if strings.HasSuffix(strings.ToLower(req.URL.Path), "/password") {
+		// The password of a credential is returned as a bare JSON string.
+		fields["response_body"] = "(omitted)"
+	} else {
+		var body string
+		body, resp.Body = peekBody(resp.Body)
+		fields["response_body"] = body
+	}
+	tflog.SubsystemTrace(ctx, apiLogSubsystem, "API response details", fields)
+
+	return resp, nil
+}
+
+// headerFields returns the headers as log fields named after the lower case
+// header names, so that secret headers are masked by their key.
+func headerFields(header http.Header) map[string]interface{} {
+	fields := make(map[string]interface{}, len(header))
+	for name, values := range header {
+		fields[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+
+	return fields
+}
+
+// peekBody returns the beginning of body for logging, and a reader replaying
+// the whole body.
+func peekBody(body io.ReadCloser) (string, io.ReadCloser) {
+	head, err := io.ReadAll(io.LimitReader(body, maxLoggedBodySize))
+
+	replay := struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), body), body}
+
+	if err != nil {
+		return "(unreadable: " + err.Error() + ")", replay
+	}
+
+	logged := string(head)
+	if len(head) == maxLoggedBodySize {
+		logged += "... (truncated)"
+	}
+
+	return logged, replay
+}
+
+// newRequestID returns a random identifier for a request.
+func newRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(id)
+}
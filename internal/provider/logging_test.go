@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+func TestLoggingTransportMasksSecrets(t *testing.T) {
+	var requestID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get(requestIDHeader)
+		_, _ = w.Write([]byte(`{"Name":"db","Password":"hunter2","Notes":"a \"quoted\" note"}`))
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	t.Setenv("TF_LOG_PROVIDER_PLEASANTPASSWORD_API", "TRACE")
+	ctx := newLoggingContext(tflogtest.RootLogger(context.Background(), &output))
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPatch, server.URL+"/api/v6/rest/credentials/42", strings.NewReader(`{"Password":"s3cr\"et"}`))
+	req.Header.Set("Authorization", "Bearer my-token")
+
+	resp, err := (&http.Client{Transport: &loggingTransport{next: http.DefaultTransport}}).Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if !strings.Contains(string(body), "hunter2") {
+		t.Fatalf("expected the response body to be passed on, got %q", body)
+	}
+
+	logs := output.String()
+	for _, secret := range []string{"my-token", "hunter2", "s3cr"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("secret %q found in logs:\n%s", secret, logs)
+		}
+	}
+	for _, expected := range []string{`"method":"PATCH"`, `"path":"/api/v6/rest/credentials/42"`, `"status":200`, `"request_id":"` + requestID + `"`, "quoted"} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("expected %s in logs:\n%s", expected, logs)
+		}
+	}
+}
+
+func TestLoggingTransportMasksCustomFields(t *testing.T) {
+	ctx := context.Background()
+
+	sensitive := types.MapValueMust(types.StringType, map[string]attr.Value{"api_key": types.StringValue("k3y-v4lue")})
+	fields, diags := customFieldsToAPI(ctx, types.MapNull(types.StringType), sensitive)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	credential := PPSClient.NewV6CredentialInputWithDefaults()
+	credential.SetName("db")
+	credential.CustomUserFields = fields
+	payload, err := json.Marshal(credential)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Name":"db","CustomUserFields":{"note":"a } brace","api_key":"k3y-v4lue"},"Notes":"kept"}`))
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	t.Setenv("TF_LOG_PROVIDER_PLEASANTPASSWORD_API", "TRACE")
+	ctx = newLoggingContext(tflogtest.RootLogger(ctx, &output))
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/api/v6/rest/entries", bytes.NewReader(payload))
+	resp, err := (&http.Client{Transport: &loggingTransport{next: http.DefaultTransport}}).Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	logs := output.String()
+	if strings.Contains(logs, "k3y-v4lue") {
+		t.Errorf("sensitive custom field found in logs:\n%s", logs)
+	}
+	if !strings.Contains(logs, "kept") {
+		t.Errorf("expected the rest of the body in logs:\n%s", logs)
+	}
+
+	// A body cut off in the middle of the custom fields is masked to its end.
+	truncated := `{"CustomUserFields":{"api_key":"k3y-v4`
+	for _, re := range secretBodyValues {
+		truncated = re.ReplaceAllString(truncated, "***")
+	}
+	if strings.Contains(truncated, "k3y") {
+		t.Errorf("sensitive custom field found in truncated body: %s", truncated)
+	}
+}
@@ -0,0 +1,10 @@
+package models
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+type Attachment struct {
+	Id            types.String `tfsdk:"id"`
+	FileName      types.String `tfsdk:"file_name"`
+	FileSize      types.Int64  `tfsdk:"file_size"`
+	ContentSHA256 types.String `tfsdk:"content_sha256"`
+}
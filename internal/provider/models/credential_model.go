@@ -3,10 +3,10 @@ package models
 import "github.com/hashicorp/terraform-plugin-framework/types"
 
 type Credential struct {
-	//
-	//	CustomUserFields map[string]interface{} `json:"CustomUserFields,omitempty"`
-	//
-	//	CustomApplicationFields    map[string]interface{} `json:"CustomApplicationFields,omitempty"`
+	CustomFields            types.Map    `tfsdk:"custom_fields"`
+	CustomApplicationFields types.Map    `tfsdk:"custom_application_fields"`
+	OTPCode                 types.String `tfsdk:"otp_code"`
+
 	Tags []Tag        `tfsdk:"tags"`
 	Id   types.String `tfsdk:"id"`
 	Name types.String `tfsdk:"name"`
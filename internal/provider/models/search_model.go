@@ -16,4 +16,8 @@ type V6CredentialSearchResult struct {
 	Notes    types.String `tfsdk:"notes"`
 	FolderId types.String `tfsdk:"folder_id"`
 	Path     types.String `tfsdk:"path"`
+
+	CustomFields            types.Map    `tfsdk:"custom_fields"`
+	CustomApplicationFields types.Map    `tfsdk:"custom_application_fields"`
+	OTPCode                 types.String `tfsdk:"otp_code"`
 }
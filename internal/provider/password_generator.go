@@ -0,0 +1,169 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	defaultPasswordLength = 32
+	// maxPasswordLength bounds the length of generated passwords, so that a
+	// typo does not allocate gigabytes during apply.
+	maxPasswordLength = 1024
+
+	lowerCharacters   = "abcdefghijklmnopqrstuvwxyz"
+	upperCharacters   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numericCharacters = "0123456789"
+	specialCharacters = "!#$%&*()-_=+[]{}<>:?"
+)
+
+// generatePasswordModel describes the generate_password block of the
+// credential resource.
+type generatePasswordModel struct {
+	Length            types.Int64  `tfsdk:"length"`
+	Lower             types.Bool   `tfsdk:"lower"`
+	Upper             types.Bool   `tfsdk:"upper"`
+	Numeric           types.Bool   `tfsdk:"numeric"`
+	Special           types.Bool   `tfsdk:"special"`
+	ExcludeCharacters types.String `tfsdk:"exclude_characters"`
+}
+
+// passwordPolicy holds the settings used to generate a password, the
+// character classes being the sets the password draws from.
+type passwordPolicy struct {
+	length  int
+	classes []string
+}
+
+// newPasswordPolicy returns the policy described by the generate_password
+// block, applying the defaults to the attributes that are not set.
+func newPasswordPolicy(m generatePasswordModel) (passwordPolicy, error) {
+	policy := passwordPolicy{length: defaultPasswordLength}
+	if !m.Length.IsNull() {
+		policy.length = int(m.Length.ValueInt64())
+	}
+
+	enabled := func(b types.Bool) bool {
+		return b.IsNull() || b.ValueBool()
+	}
+
+	exclude := m.ExcludeCharacters.ValueString()
+	for _, class := range []struct {
+		name       string
+		enabled    bool
+		characters string
+	}{
+		{"lower", enabled(m.Lower), lowerCharacters},
+		{"upper", enabled(m.Upper), upperCharacters},
+		{"numeric", enabled(m.Numeric), numericCharacters},
+		{"special", enabled(m.Special), specialCharacters},
+	} {
+		if !class.enabled {
+			continue
+		}
+
+		characters := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(exclude, r) {
+				return -1
+			}
+			return r
+		}, class.characters)
+		if characters == "" {
+			return passwordPolicy{}, fmt.Errorf("exclude_characters excludes all the %s characters", class.name)
+		}
+
+		policy.classes = append(policy.classes, characters)
+	}
+
+	if len(policy.classes) == 0 {
+		return passwordPolicy{}, errors.New("at least one of lower, upper, numeric and special must be enabled")
+	}
+	if policy.length > maxPasswordLength {
+		return passwordPolicy{}, fmt.Errorf("length must be at most %d", maxPasswordLength)
+	}
+	if policy.length < len(policy.classes) {
+		return passwordPolicy{}, fmt.Errorf("length must be at least %d to hold a character of each enabled class", len(policy.classes))
+	}
+
+	return policy, nil
+}
+
+// generate returns a random password holding at least one character of each
+// class of the policy.
+func (p passwordPolicy) generate() (string, error) {
+	password := make([]byte, 0, p.length)
+	for _, class := range p.classes {
+		c, err := randomCharacter(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	all := strings.Join(p.classes, "")
+	for len(password) < p.length {
+		c, err := randomCharacter(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// Shuffle so that the first characters are not always one of each class.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+
+	return string(password), nil
+}
+
+func randomCharacter(characters string) (byte, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(characters))))
+	if err != nil {
+		return 0, err
+	}
+
+	return characters[i.Int64()], nil
+}
+
+// passwordLengthValidator checks that the length of generated passwords is
+// between 1 and maxPasswordLength.
+type passwordLengthValidator struct{}
+
+var _ validator.Int64 = passwordLengthValidator{}
+
+func (v passwordLengthValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be between 1 and %d", maxPasswordLength)
+}
+
+func (v passwordLengthValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v passwordLengthValidator) ValidateInt64(ctx context.Context, req validator.Int64Request, resp *validator.Int64Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if length := req.ConfigValue.ValueInt64(); length < 1 || length > maxPasswordLength {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Password Length",
+			fmt.Sprintf("Expected a length between 1 and %d, got: %d.", maxPasswordLength, length),
+		)
+	}
+}
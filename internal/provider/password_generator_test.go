@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestPasswordPolicyGenerate(t *testing.T) {
+	policy, err := newPasswordPolicy(generatePasswordModel{
+		Length:            types.Int64Value(24),
+		Special:           types.BoolValue(false),
+		ExcludeCharacters: types.StringValue("0O1lI"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		password, err := policy.generate()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(password) != 24 {
+			t.Errorf("expected a password of 24 characters, got %q", password)
+		}
+		if strings.ContainsAny(password, "0O1lI"+specialCharacters) {
+			t.Errorf("password %q holds an excluded character", password)
+		}
+		for _, class := range []string{lowerCharacters, upperCharacters, numericCharacters} {
+			if !strings.ContainsAny(password, class) {
+				t.Errorf("password %q misses a character of %q", password, class)
+			}
+		}
+	}
+}
+
+func TestPasswordPolicyDefaults(t *testing.T) {
+	policy, err := newPasswordPolicy(generatePasswordModel{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if policy.length != defaultPasswordLength || len(policy.classes) != 4 {
+		t.Errorf("unexpected default policy %+v", policy)
+	}
+}
+
+func TestPasswordPolicyInvalid(t *testing.T) {
+	cases := map[string]generatePasswordModel{
+		"no class": {
+			Lower:   types.BoolValue(false),
+			Upper:   types.BoolValue(false),
+			Numeric: types.BoolValue(false),
+			Special: types.BoolValue(false),
+		},
+		"too short": {
+			Length: types.Int64Value(3),
+		},
+		"too long": {
+			Length: types.Int64Value(maxPasswordLength + 1),
+		},
+		"class excluded": {
+			ExcludeCharacters: types.StringValue(numericCharacters),
+		},
+	}
+
+	for name, settings := range cases {
+		if _, err := newPasswordPolicy(settings); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
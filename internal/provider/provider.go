@@ -5,22 +5,24 @@ package provider
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"net/http"
 	"os"
 	"strconv"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	PPSClient "github.com/theochita/go-pleasant-password"
 )
 
 var _ provider.Provider = &PleasantpasswordProvider{}
+var _ provider.ProviderWithEphemeralResources = &PleasantpasswordProvider{}
 
 type PleasantpasswordProvider struct {
 	// version is set to the provider version on release, "dev" when the
@@ -30,17 +32,44 @@ type PleasantpasswordProvider struct {
 }
 
 type PleasantpasswordProviderModel struct {
-	ServerURL      types.String `tfsdk:"server_url"`
-	Username       types.String `tfsdk:"username"`
-	Password       types.String `tfsdk:"password"`
-	Allow_insecure types.Bool   `tfsdk:"allow_insecure"`
+	ServerURL             types.String  `tfsdk:"server_url"`
+	Username              types.String  `tfsdk:"username"`
+	Password              types.String  `tfsdk:"password"`
+	Allow_insecure        types.Bool    `tfsdk:"allow_insecure"`
+	OTP                   types.String  `tfsdk:"otp"`
+	OTPProvider           types.String  `tfsdk:"otp_provider"`
+	TOTPSecret            types.String  `tfsdk:"totp_secret"`
+	CACertFile            types.String  `tfsdk:"ca_cert_file"`
+	CACertPEM             types.String  `tfsdk:"ca_cert_pem"`
+	ClientCert            types.String  `tfsdk:"client_cert"`
+	ClientKey             types.String  `tfsdk:"client_key"`
+	TLSServerName         types.String  `tfsdk:"tls_server_name"`
+	MaxRetries            types.Int64   `tfsdk:"max_retries"`
+	RetryMinWait          types.String  `tfsdk:"retry_min_wait"`
+	RetryMaxWait          types.String  `tfsdk:"retry_max_wait"`
+	RequestTimeout        types.String  `tfsdk:"request_timeout"`
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
 }
 
+// ProviderClient is handed to every resource and data source. Requests made
+// through Client are authenticated by its transport, which renews the access
+// token as needed.
 type ProviderClient struct {
 	Client PPSClient.APIClient
 	Ctx    context.Context
 }
 
+// hasUnknownValue reports whether any of the configuration values are unknown,
+// which is the case during plan when they depend on other resources.
+func (m PleasantpasswordProviderModel) hasUnknownValue() bool {
+	return m.ServerURL.IsUnknown() || m.Username.IsUnknown() || m.Password.IsUnknown() || m.Allow_insecure.IsUnknown() ||
+		m.OTP.IsUnknown() || m.OTPProvider.IsUnknown() || m.TOTPSecret.IsUnknown() ||
+		m.CACertFile.IsUnknown() || m.CACertPEM.IsUnknown() || m.ClientCert.IsUnknown() || m.ClientKey.IsUnknown() || m.TLSServerName.IsUnknown() ||
+		m.MaxRetries.IsUnknown() || m.RetryMinWait.IsUnknown() || m.RetryMaxWait.IsUnknown() || m.RequestTimeout.IsUnknown() ||
+		m.MaxConcurrentRequests.IsUnknown() || m.RequestsPerSecond.IsUnknown()
+}
+
 func (p *PleasantpasswordProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "pleasantpassword"
 	resp.Version = p.version
@@ -51,7 +80,7 @@ func (p *PleasantpasswordProvider) Schema(ctx context.Context, req provider.Sche
 		Description: `PPS API : Programatically control Pleasant Password Server.`,
 		Attributes: map[string]schema.Attribute{
 			"server_url": schema.StringAttribute{
-				MarkdownDescription: "Required: The URL of the Pleasant Password Server, Can be specified with the `PPS_SERVER_URL` environment variable  ",
+				MarkdownDescription: "Required: The URL of the Pleasant Password Server, including the port and the path prefix it is served under if any, e.g. `https://pps.example.com:10001`. HTTPS is assumed when no scheme is given, Can be specified with the `PPS_SERVER_URL` environment variable",
 				Optional:            true,
 			},
 			"password": schema.StringAttribute{
@@ -67,6 +96,65 @@ func (p *PleasantpasswordProvider) Schema(ctx context.Context, req provider.Sche
 				MarkdownDescription: "Allow insecure connections to the Pleasant Password Server, Can be specified with the `PPS_ALLOW_INSECURE` environment variable",
 				Optional:            true,
 			},
+			"otp": schema.StringAttribute{
+				MarkdownDescription: "A one-time password for users enrolled in two-factor authentication, Can be specified with the `PPS_OTP` environment variable. A code can only be used for a single login, prefer `totp_secret` for long runs",
+				Sensitive:           true,
+				Optional:            true,
+			},
+			"otp_provider": schema.StringAttribute{
+				MarkdownDescription: "The two-factor provider the one-time password is for, e.g. `authenticator-app`, Can be specified with the `PPS_OTP_PROVIDER` environment variable. Defaults to the provider requested by the server",
+				Optional:            true,
+			},
+			"totp_secret": schema.StringAttribute{
+				MarkdownDescription: "The base32 secret of the authenticator app enrolled for the user, used to compute a one-time password for every login, Can be specified with the `PPS_TOTP_SECRET` environment variable. Conflicts with `otp`",
+				Sensitive:           true,
+				Optional:            true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a PEM encoded CA bundle trusted in addition to the system roots, Can be specified with the `PPS_CA_CERT_FILE` environment variable",
+				Optional:            true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded CA certificates trusted in addition to the system roots",
+				Optional:            true,
+			},
+			"client_cert": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded client certificate, or the path to a file holding it, for servers requiring mutual TLS, Can be specified with the `PPS_CLIENT_CERT` environment variable. Requires `client_key`",
+				Optional:            true,
+			},
+			"client_key": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded private key of `client_cert`, or the path to a file holding it, Can be specified with the `PPS_CLIENT_KEY` environment variable",
+				Sensitive:           true,
+				Optional:            true,
+			},
+			"tls_server_name": schema.StringAttribute{
+				MarkdownDescription: "The server name used to verify the certificate of the Pleasant Password Server when it differs from the host of `server_url`, Can be specified with the `PPS_TLS_SERVER_NAME` environment variable",
+				Optional:            true,
+			},
+			"max_retries": schema.Int64Attribute{
+				MarkdownDescription: "How many times a request failing for a transient reason is retried, Can be specified with the `PPS_MAX_RETRIES` environment variable. Defaults to `3`, `0` disables retries. Idempotent requests are retried on network errors and on 429, 502, 503 and 504 responses, creations only on 429 and 503",
+				Optional:            true,
+			},
+			"retry_min_wait": schema.StringAttribute{
+				MarkdownDescription: "The wait before the first retry, doubled on every following one, e.g. `500ms`. Defaults to `1s`",
+				Optional:            true,
+			},
+			"retry_max_wait": schema.StringAttribute{
+				MarkdownDescription: "The longest wait between two retries, also capping the `Retry-After` header sent by the server, e.g. `1m`. Defaults to `30s`",
+				Optional:            true,
+			},
+			"request_timeout": schema.StringAttribute{
+				MarkdownDescription: "The timeout of every attempt of an API request, e.g. `30s`, Can be specified with the `PPS_REQUEST_TIMEOUT` environment variable. Defaults to no timeout",
+				Optional:            true,
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of API requests in flight at once, shared by all the resources and data sources using this provider, Can be specified with the `PPS_MAX_CONCURRENT_REQUESTS` environment variable. Defaults to no limit",
+				Optional:            true,
+			},
+			"requests_per_second": schema.Float64Attribute{
+				MarkdownDescription: "The maximum average number of API requests sent per second, shared by all the resources and data sources using this provider, e.g. `0.5` for one request every two seconds, Can be specified with the `PPS_REQUESTS_PER_SECOND` environment variable. Defaults to no limit",
+				Optional:            true,
+			},
 		},
 	}
 }
@@ -80,40 +168,29 @@ func (p *PleasantpasswordProvider) Configure(ctx context.Context, req provider.C
 		return
 	}
 
-	// If any of the expected configurations are unknown, return
-	if data.ServerURL.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("server_url"),
-			"Unknown Server URL",
-			"The provider cannot create the API client as there is unknown configuration value for the server_url",
-		)
-		return
-	}
+	// Values that come from resources or secrets created in the same run are
+	// unknown during plan. Terraform defers everything that uses the provider
+	// when it supports it. Otherwise the client is handed out anyway: resource
+	// reads keep the prior state with a warning, while data sources and
+	// ephemeral resources fail rather than return values Terraform would
+	// take as final.
+	if data.hasUnknownValue() {
+		if req.ClientCapabilities.DeferralAllowed {
+			resp.Deferred = &provider.Deferred{Reason: provider.DeferredReasonProviderConfigUnknown}
+			return
+		}
 
-	if data.Username.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("username"),
-			"Unknown Username",
-			"The provider cannot create the API client as there is unknown configuration value for the username",
-		)
-		return
-	}
+		tflog.Warn(ctx, "Provider configuration contains unknown values, API calls fail until it is known")
 
-	if data.Password.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("password"),
-			"Unknown Password",
-			"The provider cannot create the API client as there is unknown configuration value for the password",
-		)
-		return
-	}
+		cfg := PPSClient.NewConfiguration()
+		cfg.HTTPClient = &http.Client{Transport: &unavailableTransport{err: errProviderConfigUnknown}}
+		client := PPSClient.NewAPIClient(cfg)
 
-	if data.Allow_insecure.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("allow_insecure"),
-			"Unknown Allow Insecure",
-			"The provider cannot create the API client as there is unknown configuration value for the allow_insecure",
-		)
+		clientctx := newLoggingContext(context.WithoutCancel(ctx))
+
+		resp.DataSourceData = ProviderClient{Client: *client, Ctx: clientctx}
+		resp.ResourceData = ProviderClient{Client: *client, Ctx: clientctx}
+		resp.EphemeralResourceData = ProviderClient{Client: *client, Ctx: clientctx}
 		return
 	}
 
@@ -148,6 +225,73 @@ func (p *PleasantpasswordProvider) Configure(ctx context.Context, req provider.C
 		data.Allow_insecure = types.BoolValue(bool_env_ssl_insecure)
 	}
 
+	env_otp := os.Getenv("PPS_OTP")
+	if env_otp != "" && data.OTP.IsNull() {
+		data.OTP = types.StringValue(env_otp)
+	}
+
+	env_otp_provider := os.Getenv("PPS_OTP_PROVIDER")
+	if env_otp_provider != "" && data.OTPProvider.IsNull() {
+		data.OTPProvider = types.StringValue(env_otp_provider)
+	}
+
+	env_totp_secret := os.Getenv("PPS_TOTP_SECRET")
+	if env_totp_secret != "" && data.TOTPSecret.IsNull() {
+		data.TOTPSecret = types.StringValue(env_totp_secret)
+	}
+
+	env_ca_cert_file := os.Getenv("PPS_CA_CERT_FILE")
+	if env_ca_cert_file != "" && data.CACertFile.IsNull() {
+		data.CACertFile = types.StringValue(env_ca_cert_file)
+	}
+
+	env_client_cert := os.Getenv("PPS_CLIENT_CERT")
+	if env_client_cert != "" && data.ClientCert.IsNull() {
+		data.ClientCert = types.StringValue(env_client_cert)
+	}
+
+	env_client_key := os.Getenv("PPS_CLIENT_KEY")
+	if env_client_key != "" && data.ClientKey.IsNull() {
+		data.ClientKey = types.StringValue(env_client_key)
+	}
+
+	env_tls_server_name := os.Getenv("PPS_TLS_SERVER_NAME")
+	if env_tls_server_name != "" && data.TLSServerName.IsNull() {
+		data.TLSServerName = types.StringValue(env_tls_server_name)
+	}
+
+	env_max_retries := os.Getenv("PPS_MAX_RETRIES")
+	if env_max_retries != "" && data.MaxRetries.IsNull() {
+		int_env_max_retries, err := strconv.ParseInt(env_max_retries, 10, 64)
+		if err != nil {
+			resp.Diagnostics.AddError("Env MAX_RETRIES, Must be an integer", err.Error())
+		}
+		data.MaxRetries = types.Int64Value(int_env_max_retries)
+	}
+
+	env_request_timeout := os.Getenv("PPS_REQUEST_TIMEOUT")
+	if env_request_timeout != "" && data.RequestTimeout.IsNull() {
+		data.RequestTimeout = types.StringValue(env_request_timeout)
+	}
+
+	env_max_concurrent_requests := os.Getenv("PPS_MAX_CONCURRENT_REQUESTS")
+	if env_max_concurrent_requests != "" && data.MaxConcurrentRequests.IsNull() {
+		int_env_max_concurrent_requests, err := strconv.ParseInt(env_max_concurrent_requests, 10, 64)
+		if err != nil {
+			resp.Diagnostics.AddError("Env MAX_CONCURRENT_REQUESTS, Must be an integer", err.Error())
+		}
+		data.MaxConcurrentRequests = types.Int64Value(int_env_max_concurrent_requests)
+	}
+
+	env_requests_per_second := os.Getenv("PPS_REQUESTS_PER_SECOND")
+	if env_requests_per_second != "" && data.RequestsPerSecond.IsNull() {
+		float_env_requests_per_second, err := strconv.ParseFloat(env_requests_per_second, 64)
+		if err != nil {
+			resp.Diagnostics.AddError("Env REQUESTS_PER_SECOND, Must be a number", err.Error())
+		}
+		data.RequestsPerSecond = types.Float64Value(float_env_requests_per_second)
+	}
+
 	// Last check for required values that has not been set from terraform or env
 	if data.ServerURL.ValueString() == "" {
 		resp.Diagnostics.AddAttributeError(
@@ -160,6 +304,18 @@ func (p *PleasantpasswordProvider) Configure(ctx context.Context, req provider.C
 		return
 	}
 
+	serverURL, err := parseServerURL(data.ServerURL.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("server_url"),
+			"Invalid API Server URL",
+			fmt.Sprintf("The provider cannot create the API client as the value %q is not a valid URL: %s. "+
+				"Expected a value such as https://pps.example.com:10001 or https://proxy.example.com/pleasant.",
+				data.ServerURL.ValueString(), err),
+		)
+		return
+	}
+
 	if data.Username.ValueString() == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("username"),
@@ -182,52 +338,90 @@ func (p *PleasantpasswordProvider) Configure(ctx context.Context, req provider.C
 		return
 	}
 
-	if data.Allow_insecure.ValueBool() {
-		if transport, ok := http.DefaultTransport.(*http.Transport); ok {
-			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-		} else {
-			resp.Diagnostics.AddError("Failed to set InsecureSkipVerify", "Failed to set InsecureSkipVerify, this is a bug, please report it")
-		}
-	} else {
-		if transport, ok := http.DefaultTransport.(*http.Transport); ok {
-			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: false}
-		} else {
-			resp.Diagnostics.AddError("Failed to set InsecureSkipVerify", "Failed to set InsecureSkipVerify, this is a bug, please report it")
+	if data.OTP.ValueString() != "" && data.TOTPSecret.ValueString() != "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("totp_secret"),
+			"Conflicting Two-Factor Configuration",
+			"Only one of otp and totp_secret can be set, either in the configuration or through the PPS_OTP and PPS_TOTP_SECRET environment variables.",
+		)
+		return
+	}
+
+	if data.TOTPSecret.ValueString() != "" {
+		if _, err := decodeTOTPSecret(data.TOTPSecret.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("totp_secret"), "Invalid TOTP Secret", err.Error())
+			return
 		}
 	}
 
-	cfg := PPSClient.NewConfiguration()
-	cfg.Host = data.ServerURL.ValueString()
-	cfg.Scheme = "https"
-	client := PPSClient.NewAPIClient(cfg)
+	transport, diags := newTransport(tlsSettings{
+		insecure:   data.Allow_insecure.ValueBool(),
+		caCertFile: data.CACertFile.ValueString(),
+		caCertPEM:  data.CACertPEM.ValueString(),
+		clientCert: data.ClientCert.ValueString(),
+		clientKey:  data.ClientKey.ValueString(),
+		serverName: data.TLSServerName.ValueString(),
+	})
+	resp.Diagnostics.Append(diags...)
 
-	clientctx := context.Background()
-	//param := PPSClient.NewOauth2TokenInputWithDefaults()
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	grantType := "password"
-	password := data.Password.ValueString()
-	username := data.Username.ValueString()
+	// Every attempt of a retried request waits for its turn, so that retries
+	// do not get around the limits.
+	limit, diags := newLimitTransport(&loggingTransport{next: transport}, limitSettings{
+		maxConcurrentRequests: data.MaxConcurrentRequests,
+		requestsPerSecond:     data.RequestsPerSecond,
+	})
+	resp.Diagnostics.Append(diags...)
 
-	res, httperr, err := client.AuthenticationAPI.PostOauthToken(clientctx).GrantType(grantType).Username(username).Password(password).Execute()
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	if err != nil {
-		fmt.Printf("error in auth request {%s}  \n", err)
-		if httperr != nil {
-			resp.Diagnostics.AddError(fmt.Sprintf("Auth Request Failed with %s", err.Error()), fmt.Sprintf("%s", httperr.Body)) // ADD REAL ERROR MODES TO THE OPENAPI SPECC
-		} else {
-			resp.Diagnostics.AddError("Auth Request Failed", err.Error())
-		}
+	retry, diags := newRetryTransport(limit, retrySettings{
+		maxRetries:     data.MaxRetries,
+		minWait:        data.RetryMinWait,
+		maxWait:        data.RetryMaxWait,
+		requestTimeout: data.RequestTimeout,
+	})
+	resp.Diagnostics.Append(diags...)
 
+	if resp.Diagnostics.HasError() {
 		return
-	} else {
+	}
+
+	cfg := PPSClient.NewConfiguration()
+	cfg.Servers = PPSClient.ServerConfigurations{
+		{
+			URL:         serverURL,
+			Description: "Pleasant Password Server",
+		},
+	}
 
-		//fmt.Printf("Authenticated Successful\n")
-		//fmt.Printf("Bearer token: %s\n", *res.AccessToken)
-		clientctx = context.WithValue(context.Background(), PPSClient.ContextAccessToken, *res.AccessToken)
+	// The token endpoint is called through its own client so that
+	// authenticating never recurses into authTransport.
+	authcfg := *cfg
+	authcfg.HTTPClient = &http.Client{Transport: retry}
+	otp := otpSettings{
+		code:       data.OTP.ValueString(),
+		totpSecret: data.TOTPSecret.ValueString(),
+		provider:   data.OTPProvider.ValueString(),
 	}
+	tokens := newTokenSource(PPSClient.NewAPIClient(&authcfg), data.Username.ValueString(), data.Password.ValueString(), otp)
+
+	cfg.HTTPClient = &http.Client{Transport: &authTransport{tokens: tokens, next: retry}}
+	client := PPSClient.NewAPIClient(cfg)
+
+	// Authentication is deferred to the first API call, so configuring the
+	// provider never reaches out to the server. The context outlives this
+	// call but keeps its logger, so that API calls are logged.
+	clientctx := newLoggingContext(context.WithoutCancel(ctx))
 
 	resp.DataSourceData = ProviderClient{Client: *client, Ctx: clientctx}
 	resp.ResourceData = ProviderClient{Client: *client, Ctx: clientctx}
+	resp.EphemeralResourceData = ProviderClient{Client: *client, Ctx: clientctx}
 
 }
 
@@ -235,6 +429,13 @@ func (p *PleasantpasswordProvider) Resources(ctx context.Context) []func() resou
 	return []func() resource.Resource{
 		NewFolderResource,
 		NewCredentialResource,
+		NewCredentialAttachmentResource,
+	}
+}
+
+func (p *PleasantpasswordProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewCredentialEphemeralResource,
 	}
 }
 
@@ -242,6 +443,7 @@ func (p *PleasantpasswordProvider) DataSources(ctx context.Context) []func() dat
 	return []func() datasource.DataSource{
 		NewFolderDataSource,
 		NewCredentialDataSource,
+		NewCredentialAttachmentsDataSource,
 		NewSearchDataSource,
 		NewFolderRootDataSource,
 	}
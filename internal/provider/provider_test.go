@@ -4,10 +4,23 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	tfresource "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	PPSClient "github.com/theochita/go-pleasant-password"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -21,8 +34,365 @@ var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServe
 	"pleasantpassword": providerserver.NewProtocol6WithError(&PleasantpasswordProvider{"test"}),
 }
 
+// testAccProviderWrongPasswordConfig configures the provider with a password
+// the server rejects.
+const testAccProviderWrongPasswordConfig = `
+provider "pleasantpassword" {
+	password = "acctest_wrong_password"
+}
+`
+
+// testAccRFC3339Regexp matches the dates in RFC 3339 format.
+var testAccRFC3339Regexp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(Z|[+-]\d{2}:\d{2})$`)
+
+// testAccResourceID stores the ID of the resource name in id.
+func testAccResourceID(name string, id *string) tfresource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", name)
+		}
+
+		*id = rs.Primary.ID
+
+		return nil
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	// You can add code here to run prior to any test case execution, for example assertions
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+// testAccClient returns an API client configured from the PPS_* environment
+// variables, to change the server behind Terraform's back.
+func testAccClient(t *testing.T) (*PPSClient.APIClient, context.Context) {
+	t.Helper()
+
+	serverURL, err := parseServerURL(os.Getenv("PPS_SERVER_URL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insecure, _ := strconv.ParseBool(os.Getenv("PPS_ALLOW_INSECURE"))
+	transport, diags := newTransport(tlsSettings{insecure: insecure, caCertFile: os.Getenv("PPS_CA_CERT_FILE")})
+	if diags.HasError() {
+		t.Fatalf("unable to create the transport: %v", diags)
+	}
+
+	cfg := PPSClient.NewConfiguration()
+	cfg.Servers = PPSClient.ServerConfigurations{{URL: serverURL}}
+
+	authcfg := *cfg
+	authcfg.HTTPClient = &http.Client{Transport: transport}
+	otp := otpSettings{totpSecret: os.Getenv("PPS_TOTP_SECRET"), provider: os.Getenv("PPS_OTP_PROVIDER")}
+	tokens := newTokenSource(PPSClient.NewAPIClient(&authcfg), os.Getenv("PPS_USERNAME"), os.Getenv("PPS_PASSWORD"), otp)
+
+	cfg.HTTPClient = &http.Client{Transport: &authTransport{tokens: tokens, next: transport}}
+
+	return PPSClient.NewAPIClient(cfg), context.Background()
+}
+
+// testResourceRead reads the resource r with the given ID in its state, r
+// sending its requests to client.
+func testResourceRead(t *testing.T, r resource.Resource, client *PPSClient.APIClient, id string) *resource.ReadResponse {
+	t.Helper()
+
+	ctx := context.Background()
+
+	configurable, ok := r.(resource.ResourceWithConfigure)
+	if !ok {
+		t.Fatalf("%T cannot be configured", r)
+	}
+
+	configureResp := &resource.ConfigureResponse{}
+	configurable.Configure(ctx, resource.ConfigureRequest{ProviderData: ProviderClient{Client: *client, Ctx: ctx}}, configureResp)
+	if configureResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", configureResp.Diagnostics)
+	}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	stateType, isObject := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !isObject {
+		t.Fatalf("unexpected resource schema type: %T", schemaResp.Schema.Type().TerraformType(ctx))
+	}
+
+	attributes := map[string]tftypes.Value{}
+	for name, attributeType := range stateType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+	attributes["id"] = tftypes.NewValue(tftypes.String, id)
+
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(stateType, attributes)}
+	resp := &resource.ReadResponse{State: state}
+	r.Read(ctx, resource.ReadRequest{State: state}, resp)
+
+	return resp
+}
+
+// testDataSourceRead reads data source d through client with the given
+// configuration values, leaving any other attribute null.
+// testResourceConfig returns the configuration of r with the given values,
+// the other attributes being null.
+func testResourceConfig(t *testing.T, r resource.Resource, values map[string]tftypes.Value) tfsdk.Config {
+	t.Helper()
+
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	configType, isObject := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !isObject {
+		t.Fatalf("unexpected resource schema type: %T", schemaResp.Schema.Type().TerraformType(ctx))
+	}
+
+	attributes := map[string]tftypes.Value{}
+	for name, attributeType := range configType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+	for name, value := range values {
+		attributes[name] = value
+	}
+
+	return tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(configType, attributes)}
+}
+
+func testDataSourceRead(t *testing.T, d datasource.DataSource, client *PPSClient.APIClient, values map[string]tftypes.Value) *datasource.ReadResponse {
+	t.Helper()
+
+	ctx := context.Background()
+
+	configurable, ok := d.(datasource.DataSourceWithConfigure)
+	if !ok {
+		t.Fatalf("%T cannot be configured", d)
+	}
+
+	configureResp := &datasource.ConfigureResponse{}
+	configurable.Configure(ctx, datasource.ConfigureRequest{ProviderData: ProviderClient{Client: *client, Ctx: ctx}}, configureResp)
+	if configureResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", configureResp.Diagnostics)
+	}
+
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+	configType, isObject := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !isObject {
+		t.Fatalf("unexpected data source schema type: %T", schemaResp.Schema.Type().TerraformType(ctx))
+	}
+
+	attributes := map[string]tftypes.Value{}
+	for name, attributeType := range configType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+	for name, value := range values {
+		attributes[name] = value
+	}
+
+	config := tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(configType, attributes)}
+	resp := &datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw}}
+	d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+
+	return resp
+}
+
+// testProviderConfigure configures a provider server with values, leaving any
+// other provider attribute null.
+func testProviderConfigure(t *testing.T, values map[string]tftypes.Value, deferralAllowed bool) (tfprotov6.ProviderServer, *tfprotov6.ConfigureProviderResponse) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configType, ok := schemaResp.Provider.ValueType().(tftypes.Object)
+	if !ok {
+		t.Fatalf("unexpected provider schema type: %T", schemaResp.Provider.ValueType())
+	}
+
+	attributes := map[string]tftypes.Value{}
+	for name, attributeType := range configType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+	for name, value := range values {
+		attributes[name] = value
+	}
+
+	config, err := tfprotov6.NewDynamicValue(configType, tftypes.NewValue(configType, attributes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{
+		Config:             &config,
+		ClientCapabilities: &tfprotov6.ConfigureProviderClientCapabilities{DeferralAllowed: deferralAllowed},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return server, resp
+}
+
+func TestProviderConfigureDefersUnknownConfiguration(t *testing.T) {
+	server, resp := testProviderConfigure(t, map[string]tftypes.Value{
+		"server_url": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"username":   tftypes.NewValue(tftypes.String, "admin"),
+		"password":   tftypes.NewValue(tftypes.String, "secret"),
+	}, true)
+
+	if len(resp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	configType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{"id": tftypes.String}}
+	config, err := tfprotov6.NewDynamicValue(configType, tftypes.NewValue(configType, map[string]tftypes.Value{
+		"id": tftypes.NewValue(tftypes.String, nil),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readResp, err := server.ReadDataSource(context.Background(), &tfprotov6.ReadDataSourceRequest{
+		TypeName:           "pleasantpassword_folder_root",
+		Config:             &config,
+		ClientCapabilities: &tfprotov6.ReadDataSourceClientCapabilities{DeferralAllowed: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if readResp.Deferred == nil || readResp.Deferred.Reason != tfprotov6.DeferredReasonProviderConfigUnknown {
+		t.Fatalf("expected the data source read to be deferred, got %v", readResp.Deferred)
+	}
+}
+
+func TestProviderConfigureAcceptsUnknownConfigurationWithoutDeferral(t *testing.T) {
+	server, resp := testProviderConfigure(t, map[string]tftypes.Value{
+		"server_url": tftypes.NewValue(tftypes.String, "pps.example.com"),
+		"username":   tftypes.NewValue(tftypes.String, "admin"),
+		"password":   tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+	}, false)
+
+	if len(resp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	configType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{"id": tftypes.String}}
+	config, err := tfprotov6.NewDynamicValue(configType, tftypes.NewValue(configType, map[string]tftypes.Value{
+		"id": tftypes.NewValue(tftypes.String, nil),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Data sources cannot return unknown values, they fail rather than have
+	// Terraform take null values as final.
+	readResp, err := server.ReadDataSource(context.Background(), &tfprotov6.ReadDataSourceRequest{
+		TypeName: "pleasantpassword_folder_root",
+		Config:   &config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(readResp.Diagnostics) != 1 || readResp.Diagnostics[0].Severity != tfprotov6.DiagnosticSeverityError || readResp.Diagnostics[0].Summary != "Provider Configuration Unknown" {
+		t.Fatalf("expected a single error, got: %v", readResp.Diagnostics)
+	}
+
+	schemaResp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ephemeralType, ok := schemaResp.EphemeralResourceSchemas["pleasantpassword_credential"].ValueType().(tftypes.Object)
+	if !ok {
+		t.Fatal("unexpected ephemeral resource schema type")
+	}
+	attributes := map[string]tftypes.Value{}
+	for name, attributeType := range ephemeralType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+	attributes["credential_id"] = tftypes.NewValue(tftypes.String, "42")
+	ephemeralConfig, err := tfprotov6.NewDynamicValue(ephemeralType, tftypes.NewValue(ephemeralType, attributes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	openResp, err := server.OpenEphemeralResource(context.Background(), &tfprotov6.OpenEphemeralResourceRequest{
+		TypeName: "pleasantpassword_credential",
+		Config:   &ephemeralConfig,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(openResp.Diagnostics) != 1 || openResp.Diagnostics[0].Summary != "Provider Configuration Unknown" {
+		t.Fatalf("expected a single error, got: %v", openResp.Diagnostics)
+	}
+}
+
+func TestResourceReadKeepsStateWhileConfigUnknown(t *testing.T) {
+	client := PPSClient.NewAPIClient(PPSClient.NewConfiguration())
+	client.GetConfig().HTTPClient = &http.Client{Transport: &unavailableTransport{err: errProviderConfigUnknown}}
+
+	resp := testResourceRead(t, NewFolderResource(), client, "42")
+
+	if resp.Diagnostics.HasError() || resp.Diagnostics.WarningsCount() != 1 {
+		t.Fatalf("expected a single warning, got: %v", resp.Diagnostics)
+	}
+	if resp.State.Raw.IsNull() {
+		t.Error("expected the prior state to be kept")
+	}
+}
+
+func TestProviderConfigureDoesNotAuthenticate(t *testing.T) {
+	// Nothing listens on the discard port, configuring must succeed anyway
+	// as authentication only happens on the first API call.
+	_, resp := testProviderConfigure(t, map[string]tftypes.Value{
+		"server_url": tftypes.NewValue(tftypes.String, "127.0.0.1:9"),
+		"username":   tftypes.NewValue(tftypes.String, "admin"),
+		"password":   tftypes.NewValue(tftypes.String, "secret"),
+	}, false)
+
+	if len(resp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+}
+
+func TestProviderConfigureRejectsInvalidServerURL(t *testing.T) {
+	_, resp := testProviderConfigure(t, map[string]tftypes.Value{
+		"server_url": tftypes.NewValue(tftypes.String, "ftp://pps.example.com"),
+		"username":   tftypes.NewValue(tftypes.String, "admin"),
+		"password":   tftypes.NewValue(tftypes.String, "secret"),
+	}, false)
+
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary != "Invalid API Server URL" {
+		t.Fatalf("expected an invalid server URL diagnostic, got: %v", resp.Diagnostics)
+	}
+}
+
+func TestProviderConfigureRejectsInvalidRetrySettings(t *testing.T) {
+	_, resp := testProviderConfigure(t, map[string]tftypes.Value{
+		"server_url":     tftypes.NewValue(tftypes.String, "https://pps.example.com"),
+		"username":       tftypes.NewValue(tftypes.String, "admin"),
+		"password":       tftypes.NewValue(tftypes.String, "secret"),
+		"retry_min_wait": tftypes.NewValue(tftypes.String, "1 minute"),
+	}, false)
+
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary != "Invalid Duration" {
+		t.Fatalf("expected an invalid duration diagnostic, got: %v", resp.Diagnostics)
+	}
+}
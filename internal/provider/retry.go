@@ -0,0 +1,243 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	defaultMaxRetries   = 3
+	defaultRetryMinWait = time.Second
+	defaultRetryMaxWait = 30 * time.Second
+)
+
+// idempotentMethods can be sent again whatever happened to the first attempt.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+}
+
+// retryableStatus are the responses of a load balancer or an overloaded server
+// that are worth trying again.
+var retryableStatus = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// unprocessedStatus are the retryable responses that guarantee the server did
+// not act on the request, so that it is safe to send a POST again.
+var unprocessedStatus = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusServiceUnavailable: true,
+}
+
+// retryTransport retries requests that failed for transient reasons, waiting
+// with exponential backoff between attempts.
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	minWait    time.Duration
+	maxWait    time.Duration
+	// timeout bounds every attempt, including reading the response body, it
+	// is disabled when zero.
+	timeout time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.roundTrip(attemptReq)
+
+		if attempt >= t.maxRetries || !t.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// roundTrip sends a single attempt. With a timeout, the response body is read
+// before returning so that the attempt's context can be released.
+func (t *retryTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	defer cancel()
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+// retryable reports whether req should be sent again after the given outcome.
+// Idempotent requests are retried on network errors and on any retryable
+// status, other requests only when the server did not process them.
+func (t *retryTransport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return idempotentMethods[req.Method]
+	}
+
+	if idempotentMethods[req.Method] {
+		return retryableStatus[resp.StatusCode]
+	}
+
+	return unprocessedStatus[resp.StatusCode]
+}
+
+// backoff returns how long to wait before the next attempt, honoring the
+// Retry-After header of the response if any, capped to maxWait.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(max(wait, t.minWait), t.maxWait)
+		}
+	}
+
+	wait := t.minWait << attempt
+	if wait <= 0 || wait > t.maxWait {
+		wait = t.maxWait
+	}
+
+	// Spread out the retries of parallel requests failing together.
+	jitter := time.Duration(rand.Int63n(int64(wait)/4 + 1))
+
+	return min(wait+jitter, t.maxWait)
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+
+	return 0, false
+}
+
+// retrySettings are the retry related attributes of the provider
+// configuration.
+type retrySettings struct {
+	maxRetries     types.Int64
+	minWait        types.String
+	maxWait        types.String
+	requestTimeout types.String
+}
+
+// newRetryTransport validates settings and returns a retryTransport sending
+// requests through next, using the defaults for the unset attributes.
+func newRetryTransport(next http.RoundTripper, settings retrySettings) (*retryTransport, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	t := &retryTransport{
+		next:       next,
+		maxRetries: defaultMaxRetries,
+		minWait:    parseDurationAttribute(path.Root("retry_min_wait"), settings.minWait, defaultRetryMinWait, &diags),
+		maxWait:    parseDurationAttribute(path.Root("retry_max_wait"), settings.maxWait, defaultRetryMaxWait, &diags),
+		timeout:    parseDurationAttribute(path.Root("request_timeout"), settings.requestTimeout, 0, &diags),
+	}
+
+	if !settings.maxRetries.IsNull() {
+		if settings.maxRetries.ValueInt64() < 0 {
+			diags.AddAttributeError(path.Root("max_retries"), "Invalid Maximum Retries", "max_retries must be zero or greater.")
+		}
+		t.maxRetries = int(settings.maxRetries.ValueInt64())
+	}
+
+	if t.minWait > t.maxWait {
+		diags.AddAttributeError(path.Root("retry_min_wait"), "Invalid Retry Wait", "retry_min_wait must not be greater than retry_max_wait.")
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return t, diags
+}
+
+// parseDurationAttribute parses a Go duration string such as "500ms" or "1m",
+// returning def when value is null.
+func parseDurationAttribute(p path.Path, value types.String, def time.Duration, diags *diag.Diagnostics) time.Duration {
+	if value.IsNull() {
+		return def
+	}
+
+	d, err := time.ParseDuration(value.ValueString())
+	if err != nil {
+		diags.AddAttributeError(p, "Invalid Duration", fmt.Sprintf("%q is not a valid duration, expected a value such as \"500ms\", \"10s\" or \"1m\": %s", value.ValueString(), err))
+		return def
+	}
+	if d < 0 {
+		diags.AddAttributeError(p, "Invalid Duration", "The duration must not be negative.")
+		return def
+	}
+
+	return d
+}
@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestRetryServer answers with the given status codes in turn, then with
+// 200 OK, counting the requests in calls.
+func newTestRetryServer(t *testing.T, calls *int32, statuses ...int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		n := int(atomic.AddInt32(calls, 1))
+		if n <= len(statuses) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestRetryClient(maxRetries int) *http.Client {
+	return &http.Client{Transport: &retryTransport{
+		next:       http.DefaultTransport,
+		maxRetries: maxRetries,
+		minWait:    time.Millisecond,
+		maxWait:    10 * time.Millisecond,
+	}}
+}
+
+func TestRetryTransportRetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	server := newTestRetryServer(t, &calls, http.StatusServiceUnavailable, http.StatusBadGateway)
+
+	req, _ := http.NewRequest(http.MethodPatch, server.URL, strings.NewReader("payload"))
+	resp, err := newTestRetryClient(3).Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "payload" {
+		t.Fatalf("expected the body to be replayed with 200 OK, got %d %q", resp.StatusCode, body)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRetryTransportDoesNotRetryProcessedPost(t *testing.T) {
+	var calls int32
+	server := newTestRetryServer(t, &calls, http.StatusBadGateway)
+
+	resp, err := newTestRetryClient(3).Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected 502 Bad Gateway, got %d", resp.StatusCode)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected a single attempt, got %d", n)
+	}
+}
+
+func TestRetryTransportGivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	server := newTestRetryServer(t, &calls, http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests)
+
+	resp, err := newTestRetryClient(1).Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 Too Many Requests, got %d", resp.StatusCode)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected 2 attempts, got %d", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if wait, ok := retryAfter("7"); !ok || wait != 7*time.Second {
+		t.Errorf("expected 7s, got %s", wait)
+	}
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if wait, ok := retryAfter(date); !ok || wait <= 50*time.Second || wait > time.Minute {
+		t.Errorf("expected about a minute, got %s", wait)
+	}
+
+	if _, ok := retryAfter("soon"); ok {
+		t.Error("expected an invalid Retry-After to be ignored")
+	}
+}
+
+func TestRetryTransportBackoffIsCapped(t *testing.T) {
+	transport := &retryTransport{minWait: time.Second, maxWait: 5 * time.Second}
+
+	for attempt := 0; attempt < 70; attempt++ {
+		if wait := transport.backoff(attempt, nil); wait < time.Second || wait > 5*time.Second {
+			t.Fatalf("attempt %d: wait %s out of bounds", attempt, wait)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3600"}}}
+	if wait := transport.backoff(0, resp); wait != 5*time.Second {
+		t.Fatalf("expected Retry-After to be capped to 5s, got %s", wait)
+	}
+}
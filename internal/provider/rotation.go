@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// rotationModel describes the rotation block of the credential resource.
+type rotationModel struct {
+	Interval types.String `tfsdk:"interval"`
+}
+
+// rotationUnits are the units of a rotation interval that time.ParseDuration
+// does not support.
+var rotationUnits = map[byte]time.Duration{
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseRotationInterval parses a rotation interval, a number of days or weeks
+// such as 90d or 2w, or a duration such as 12h.
+func parseRotationInterval(value string) (time.Duration, error) {
+	var interval time.Duration
+
+	if n := len(value); n > 1 && rotationUnits[value[n-1]] != 0 {
+		count, err := strconv.Atoi(value[:n-1])
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid interval, e.g. 90d, 2w or 12h", value)
+		}
+		interval = time.Duration(count) * rotationUnits[value[n-1]]
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid interval, e.g. 90d, 2w or 12h", value)
+		}
+		interval = d
+	}
+
+	if interval <= 0 {
+		return 0, fmt.Errorf("%q is not a positive interval", value)
+	}
+
+	return interval, nil
+}
+
+// rotationDue reports whether a password last rotated at lastRotated must be
+// rotated at now. A password whose last rotation is not known, e.g. after an
+// import, is due.
+func rotationDue(lastRotated types.String, interval time.Duration, now time.Time) bool {
+	if lastRotated.IsNull() || lastRotated.IsUnknown() {
+		return true
+	}
+
+	t, err := time.Parse(time.RFC3339, lastRotated.ValueString())
+	if err != nil {
+		return true
+	}
+
+	return !now.Before(t.Add(interval))
+}
+
+// rotationIntervalValidator checks that a string attribute holds a rotation
+// interval.
+type rotationIntervalValidator struct{}
+
+var _ validator.String = rotationIntervalValidator{}
+
+func (v rotationIntervalValidator) Description(ctx context.Context) string {
+	return "value must be a number of days or weeks such as 90d or 2w, or a duration such as 12h"
+}
+
+func (v rotationIntervalValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v rotationIntervalValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := parseRotationInterval(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Rotation Interval", err.Error()+".")
+	}
+}
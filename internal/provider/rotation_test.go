@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestParseRotationInterval(t *testing.T) {
+	cases := map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+		"90m": 90 * time.Minute,
+	}
+
+	for value, expected := range cases {
+		interval, err := parseRotationInterval(value)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", value, err)
+		} else if interval != expected {
+			t.Errorf("%s: expected %s, got %s", value, expected, interval)
+		}
+	}
+
+	for _, value := range []string{"", "d", "90", "ninety days", "0d", "-1w"} {
+		if _, err := parseRotationInterval(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+func TestRotationDue(t *testing.T) {
+	now := time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC)
+	interval := 30 * 24 * time.Hour
+
+	cases := []struct {
+		lastRotated types.String
+		expected    bool
+	}{
+		{types.StringValue("2030-03-15T00:00:00Z"), false},
+		{types.StringValue("2030-03-02T00:00:00Z"), true},
+		{types.StringValue("2030-01-01T00:00:00Z"), true},
+		{types.StringNull(), true},
+	}
+
+	for _, c := range cases {
+		if actual := rotationDue(c.lastRotated, interval, now); actual != c.expected {
+			t.Errorf("%s: expected %t, got %t", c.lastRotated, c.expected, actual)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	PPSClient "github.com/theochita/go-pleasant-password"
@@ -28,9 +30,10 @@ type SearchDataSource struct {
 }
 
 type SearchDataSourceModel struct {
-	Search      types.String                           `tfsdk:"search"`
-	Credentials []models.V6CredentialSearchResult      `tfsdk:"credentials"`
-	Folders     []models.V6CredentialGroupSearchResult `tfsdk:"folders"`
+	Search              types.String                           `tfsdk:"search"`
+	IncludeCustomFields types.Bool                             `tfsdk:"include_custom_fields"`
+	Credentials         []models.V6CredentialSearchResult      `tfsdk:"credentials"`
+	Folders             []models.V6CredentialGroupSearchResult `tfsdk:"folders"`
 }
 
 func (d SearchDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -47,6 +50,10 @@ func (d *SearchDataSource) Schema(ctx context.Context, req datasource.SchemaRequ
 				MarkdownDescription: "The search query for credentials and folders.",
 				Required:            true,
 			},
+			"include_custom_fields": schema.BoolAttribute{
+				MarkdownDescription: "Whether to read the custom fields of the credentials found, which takes one more request per credential. Defaults to `false`.",
+				Optional:            true,
+			},
 			"credentials": schema.ListNestedAttribute{
 				Computed: true,
 				NestedObject: schema.NestedAttributeObject{
@@ -79,6 +86,22 @@ func (d *SearchDataSource) Schema(ctx context.Context, req datasource.SchemaRequ
 							MarkdownDescription: "The path of the credential.",
 							Computed:            true,
 						},
+						"custom_fields": schema.MapAttribute{
+							MarkdownDescription: "The custom user fields of the credential, all marked as sensitive. Only read when `include_custom_fields` is `true`.",
+							ElementType:         types.StringType,
+							Sensitive:           true,
+							Computed:            true,
+						},
+						"custom_application_fields": schema.MapAttribute{
+							MarkdownDescription: "The custom application fields of the credential. Only read when `include_custom_fields` is `true`.",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+						"otp_code": schema.StringAttribute{
+							MarkdownDescription: "The current TOTP code of the credential, computed from the TOTP properties of the credential, or null when it has none or the user may not view it. It is also null, with a warning, when the TOTP settings cannot be used. Only read when `include_custom_fields` is `true`.",
+							Sensitive:           true,
+							Computed:            true,
+						},
 					},
 				},
 			},
@@ -138,12 +161,30 @@ func (d *SearchDataSource) fetchCredentials(res []PPSClient.V6CredentialSearchRe
 		cred.Notes = types.StringValue(v.GetNotes())
 		cred.FolderId = types.StringValue(v.GetGroupId())
 		cred.Path = types.StringValue(v.GetPath())
+		cred.CustomFields = types.MapNull(types.StringType)
+		cred.CustomApplicationFields = types.MapNull(types.StringType)
+		cred.OTPCode = types.StringNull()
 		creds = append(creds, cred)
 
 	}
 	return creds
 }
 
+// fetchCustomFields reads the custom fields of the credentials found, which
+// the search results do not include.
+func (d *SearchDataSource) fetchCustomFields(creds []models.V6CredentialSearchResult, diags *diag.Diagnostics) {
+	for i := range creds {
+		res, httpres, err := d.client.DefaultAPI.GetV6CredentialsByID(*d.ctx, creds[i].Id.ValueString()).Execute()
+		if !checkAPIResponse(diags, apiOperation{action: "read the custom fields of the credential", permission: "View", object: fmt.Sprintf("credential %q", creds[i].Id.ValueString())}, httpres, err, 200) {
+			return
+		}
+
+		creds[i].OTPCode = readListedOTPCode(res, path.Root("credentials").AtListIndex(i).AtName("otp_code"), diags)
+		creds[i].CustomFields = customFieldsValue(res.CustomUserFields)
+		creds[i].CustomApplicationFields = customFieldsValue(res.CustomApplicationFields)
+	}
+}
+
 func (d *SearchDataSource) fetchFolders(res []PPSClient.V6CredentialGroupSearchResult) []models.V6CredentialGroupSearchResult {
 	var folders = []models.V6CredentialGroupSearchResult{}
 	for _, v := range res {
@@ -158,6 +199,10 @@ func (d *SearchDataSource) fetchFolders(res []PPSClient.V6CredentialGroupSearchR
 }
 
 func (d *SearchDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if failWhileConfigUnknown(d.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data SearchDataSourceModel
 
 	// Read Terraform configuration data into the model
@@ -173,12 +218,7 @@ func (d *SearchDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	params.Search = data.Search.ValueStringPointer()
 
 	res, httpres, err := client.DefaultAPI.PostV6Search(*d.ctx).V6SearchInput(*params).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError("failure to invoke API: ", err.Error())
-		return
-	}
-	if httpres.StatusCode != 200 {
-		resp.Diagnostics.AddError("Got an unexpected response code", fmt.Sprintf("Got an unexpected response code %v", httpres.StatusCode))
+	if !checkAPIResponse(&resp.Diagnostics, apiOperation{action: "search credentials and folders"}, httpres, err, 200) {
 		return
 	}
 
@@ -187,11 +227,15 @@ func (d *SearchDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	}
 
 	data.Credentials = d.fetchCredentials(res.Credentials)
+	if data.IncludeCustomFields.ValueBool() {
+		d.fetchCustomFields(data.Credentials, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
 	data.Folders = d.fetchFolders(res.Groups)
 
-	// Write logs using the tflog package
-	// Documentation: https://terraform.io/plugin/log
-	tflog.Trace(ctx, "read a data source")
+	tflog.Trace(ctx, "Searched credentials and folders", map[string]interface{}{"query": data.Search.ValueString()})
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
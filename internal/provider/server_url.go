@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// parseServerURL validates server_url and returns the base URL the API paths
+// are appended to. A value without a scheme, such as "pps.example.com:10001",
+// is taken to be served over HTTPS.
+func parseServerURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	serverURL, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	scheme := strings.ToLower(serverURL.Scheme)
+	if scheme != "https" && scheme != "http" {
+		return "", fmt.Errorf("unsupported scheme %q, expected https or http", serverURL.Scheme)
+	}
+
+	if serverURL.Hostname() == "" {
+		return "", fmt.Errorf("the URL has no host")
+	}
+
+	if serverURL.User != nil {
+		return "", fmt.Errorf("the URL must not contain credentials, use the username and password attributes")
+	}
+
+	if serverURL.RawQuery != "" || serverURL.Fragment != "" {
+		return "", fmt.Errorf("the URL must not contain a query or a fragment")
+	}
+
+	base := url.URL{
+		Scheme: scheme,
+		Host:   serverURL.Host,
+		Path:   strings.TrimRight(serverURL.Path, "/"),
+	}
+
+	return base.String(), nil
+}
@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// datePlaceholder was stored in the date attributes before they were read
+// from the API.
+const datePlaceholder = "Not implemented"
+
+// apiTimeLayouts are the layouts of the dates returned by the API, which omits
+// the time zone of UTC dates.
+var apiTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseAPITime parses a date returned by the API, dates without a time zone
+// being in UTC.
+func parseAPITime(value string) (time.Time, error) {
+	for _, layout := range apiTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a date in RFC 3339 format", value)
+}
+
+// timestampValue returns t in RFC 3339 format, null when the API did not
+// return it.
+func timestampValue(t *time.Time) types.String {
+	if t == nil || t.IsZero() {
+		return types.StringNull()
+	}
+
+	return types.StringValue(t.UTC().Format(time.RFC3339))
+}
+
+// expiresValue returns the expiration date returned by the API in RFC 3339
+// format, null when the object does not expire. The prior value is kept when
+// it designates the same instant, so that the date is not reformatted.
+func expiresValue(expires *string, prior types.String) types.String {
+	if expires == nil || *expires == "" {
+		return types.StringNull()
+	}
+
+	t, err := parseAPITime(*expires)
+	if err != nil {
+		return types.StringValue(*expires)
+	}
+
+	if !prior.IsNull() && !prior.IsUnknown() {
+		if p, err := time.Parse(time.RFC3339, prior.ValueString()); err == nil && p.Equal(t) {
+			return prior
+		}
+	}
+
+	return types.StringValue(t.UTC().Format(time.RFC3339))
+}
+
+// expiresToAPI returns the expiration date to send to the API, nil when it is
+// not set.
+func expiresToAPI(expires types.String) *string {
+	if expires.IsNull() || expires.IsUnknown() {
+		return nil
+	}
+
+	return expires.ValueStringPointer()
+}
+
+// rfc3339Validator checks that a string attribute holds a date in RFC 3339
+// format.
+type rfc3339Validator struct{}
+
+var _ validator.String = rfc3339Validator{}
+
+func (v rfc3339Validator) Description(ctx context.Context) string {
+	return "value must be a date in RFC 3339 format, e.g. 2030-01-31T00:00:00Z"
+}
+
+func (v rfc3339Validator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v rfc3339Validator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Date", fmt.Sprintf("%q is not a date in RFC 3339 format, e.g. 2030-01-31T00:00:00Z.", req.ConfigValue.ValueString()))
+	}
+}
+
+// upgradePlaceholderDates upgrades a state where created, modified and expires
+// may hold datePlaceholder, replacing it by null. The other attributes are
+// kept as they are.
+func upgradePlaceholderDates(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	if req.RawState == nil {
+		resp.Diagnostics.AddError("Unable to upgrade the state", "The prior state is missing.")
+		return
+	}
+
+	var state map[string]interface{}
+	if err := json.Unmarshal(req.RawState.JSON, &state); err != nil {
+		resp.Diagnostics.AddError("Unable to upgrade the state", err.Error())
+		return
+	}
+
+	for _, name := range []string{"created", "modified", "expires"} {
+		if state[name] == datePlaceholder {
+			state[name] = nil
+		}
+	}
+
+	upgraded, err := json.Marshal(state)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to upgrade the state", err.Error())
+		return
+	}
+
+	resp.DynamicValue = &tfprotov6.DynamicValue{JSON: upgraded}
+}
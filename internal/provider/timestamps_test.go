@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+func TestExpiresValue(t *testing.T) {
+	cases := []struct {
+		expires  *string
+		prior    types.String
+		expected types.String
+	}{
+		{nil, types.StringValue("2030-01-31T00:00:00Z"), types.StringNull()},
+		{stringPointer("2030-01-31T00:00:00"), types.StringNull(), types.StringValue("2030-01-31T00:00:00Z")},
+		{stringPointer("2030-01-31T00:00:00"), types.StringValue("2030-01-31T01:00:00+01:00"), types.StringValue("2030-01-31T01:00:00+01:00")},
+		{stringPointer("2030-02-01T00:00:00Z"), types.StringValue("2030-01-31T00:00:00Z"), types.StringValue("2030-02-01T00:00:00Z")},
+	}
+
+	for _, c := range cases {
+		if actual := expiresValue(c.expires, c.prior); !actual.Equal(c.expected) {
+			t.Errorf("expected %s, got %s", c.expected, actual)
+		}
+	}
+}
+
+func TestUpgradePlaceholderDates(t *testing.T) {
+	req := resource.UpgradeStateRequest{RawState: &tfprotov6.RawState{
+		JSON: []byte(`{"id":"42","name":"db","created":"Not implemented","modified":"Not implemented","expires":"2030-01-31T00:00:00Z"}`),
+	}}
+	resp := &resource.UpgradeStateResponse{}
+
+	upgradePlaceholderDates(context.Background(), req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var state map[string]interface{}
+	if err := json.Unmarshal(resp.DynamicValue.JSON, &state); err != nil {
+		t.Fatal(err)
+	}
+
+	if state["created"] != nil || state["modified"] != nil || state["expires"] != "2030-01-31T00:00:00Z" || state["name"] != "db" {
+		t.Fatalf("unexpected upgraded state: %v", state)
+	}
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
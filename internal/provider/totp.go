@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+const (
+	totpDefaultDigits = 6
+	totpDefaultPeriod = 30
+)
+
+// decodeTOTPSecret decodes a base32 TOTP secret, as shown by authenticator
+// apps, ignoring case, spaces and padding.
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	normalized := strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "=", "").Replace(secret))
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("the TOTP secret is not valid base32: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("the TOTP secret is empty")
+	}
+
+	return key, nil
+}
+
+// totpCode computes the RFC 6238 time-based one-time password of secret at t,
+// with HMAC-SHA1 as Pleasant Password Server and authenticator apps do.
+func totpCode(secret string, t time.Time, digits int, period int64) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	if digits < 6 || digits > 10 {
+		return "", fmt.Errorf("TOTP digits must be between 6 and 10, got %d", digits)
+	}
+
+	if period <= 0 {
+		return "", fmt.Errorf("TOTP period must be positive, got %d", period)
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(t.Unix()/period))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, RFC 4226 section 5.3.
+	offset := sum[len(sum)-1] & 0x0f
+	value := uint64(binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff)
+
+	modulo := uint64(1)
+	for i := 0; i < digits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%modulo), nil
+}
+
+// totpSettings are the settings of the TOTP generator of a credential, zero
+// values standing for the defaults. The server stores no algorithm, the codes
+// are computed with SHA-1 as authenticator apps do by default.
+type totpSettings struct {
+	secret string
+	issuer string
+	digits int
+	period int64
+}
+
+// code computes the code of the generator at t.
+func (s totpSettings) code(t time.Time) (string, error) {
+	return totpCode(s.secret, t, s.digitsOrDefault(), s.periodOrDefault())
+}
+
+func (s totpSettings) digitsOrDefault() int {
+	if s.digits == 0 {
+		return totpDefaultDigits
+	}
+
+	return s.digits
+}
+
+func (s totpSettings) periodOrDefault() int64 {
+	if s.period == 0 {
+		return totpDefaultPeriod
+	}
+
+	return s.period
+}
+
+// canViewTOTP reports whether the user may view the TOTP generator of a
+// credential returned by the API. Servers that do not return the access are
+// assumed to allow it.
+func canViewTOTP(res *PPSClient.V6CredentialResult) bool {
+	access, ok := res.GetHasViewTOTPAccessOk()
+	return !ok || *access
+}
+
+// apiTOTPSettings returns the settings of the TOTP generator of a credential
+// returned by the API, ok being false when it has none or the user may not
+// view it.
+func apiTOTPSettings(res *PPSClient.V6CredentialResult) (settings totpSettings, ok bool) {
+	if !canViewTOTP(res) || res.GetTOTPSecret() == "" {
+		return totpSettings{}, false
+	}
+
+	return totpSettings{
+		secret: res.GetTOTPSecret(),
+		issuer: res.GetTOTPIssuer(),
+		digits: int(res.GetTOTPDigits()),
+		period: int64(res.GetTOTPPeriod()),
+	}, true
+}
+
+// readOTPCode returns the current code of the TOTP generator of a credential
+// returned by the API, and reports a generator that cannot be used to diags
+// at p.
+func readOTPCode(res *PPSClient.V6CredentialResult, p path.Path, diags *diag.Diagnostics) types.String {
+	code, err := otpCodeValue(res, time.Now())
+	if err != nil {
+		diags.AddAttributeError(p, "Unable to compute the OTP code", err.Error()+".")
+	}
+
+	return code
+}
+
+// readListedOTPCode returns the current code of the TOTP generator of a
+// credential listed by the folder or search data source. A generator that
+// cannot be used, set up outside of Terraform, does not fail the whole list:
+// its code is null and a warning is reported to diags at p.
+func readListedOTPCode(res *PPSClient.V6CredentialResult, p path.Path, diags *diag.Diagnostics) types.String {
+	code, err := otpCodeValue(res, time.Now())
+	if err != nil {
+		diags.AddAttributeWarning(p, "Unable to compute the OTP code", fmt.Sprintf("The OTP code of credential %q is left null: %s.", res.GetId(), err))
+	}
+
+	return code
+}
+
+// otpCodeValue returns the code at now of the TOTP generator of a credential
+// returned by the API, or null when the credential has none.
+func otpCodeValue(res *PPSClient.V6CredentialResult, now time.Time) (types.String, error) {
+	settings, ok := apiTOTPSettings(res)
+	if !ok {
+		return types.StringNull(), nil
+	}
+
+	code, err := settings.code(now)
+	if err != nil {
+		return types.StringNull(), err
+	}
+
+	return types.StringValue(code), nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	PPSClient "github.com/theochita/go-pleasant-password"
+)
+
+// TestTOTPCode checks totpCode against the SHA-1 test vectors of RFC 6238
+// appendix B.
+func TestTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	for unix, expected := range map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	} {
+		code, err := totpCode(secret, time.Unix(unix, 0), 8, 30)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if code != expected {
+			t.Errorf("at %d: expected %s, got %s", unix, expected, code)
+		}
+	}
+}
+
+func TestTOTPCodeInvalidSecret(t *testing.T) {
+	if _, err := totpCode("not base32!", time.Now(), 6, 30); err == nil {
+		t.Fatal("expected an error for an invalid secret")
+	}
+}
+
+func TestOTPCodeValue(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	res := &PPSClient.V6CredentialResult{
+		TOTPSecret: PPSClient.PtrString(secret),
+		TOTPDigits: PPSClient.PtrInt32(8),
+		TOTPPeriod: PPSClient.PtrInt32(30),
+	}
+	code, err := otpCodeValue(res, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if code.ValueString() != "07081804" {
+		t.Errorf("expected 07081804, got %s", code)
+	}
+
+	res.HasViewTOTPAccess = PPSClient.PtrBool(false)
+	code, err = otpCodeValue(res, now)
+	if err != nil || !code.IsNull() {
+		t.Errorf("expected a null code without the view TOTP access, got %s, %v", code, err)
+	}
+
+	code, err = otpCodeValue(&PPSClient.V6CredentialResult{}, now)
+	if err != nil || !code.IsNull() {
+		t.Errorf("expected a null code without a TOTP secret, got %s, %v", code, err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// tlsSettings configure how a provider instance verifies the server and
+// authenticates itself at the TLS layer.
+type tlsSettings struct {
+	insecure   bool
+	caCertFile string
+	caCertPEM  string
+	clientCert string
+	clientKey  string
+	serverName string
+}
+
+// newTransport returns an HTTP transport dedicated to a provider instance, so
+// that provider aliases with different TLS settings do not affect each other.
+func newTransport(settings tlsSettings) (*http.Transport, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		diags.AddError("Failed to create the HTTP transport", "http.DefaultTransport is not an *http.Transport, this is a bug, please report it")
+		return nil, diags
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: settings.insecure,
+		ServerName:         settings.serverName,
+	}
+
+	if settings.caCertFile != "" || settings.caCertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if settings.caCertFile != "" {
+			pem, err := os.ReadFile(settings.caCertFile)
+			if err != nil {
+				diags.AddAttributeError(path.Root("ca_cert_file"), "Unable to read the CA certificate file", err.Error())
+			} else if !pool.AppendCertsFromPEM(pem) {
+				diags.AddAttributeError(path.Root("ca_cert_file"), "Invalid CA certificate file", fmt.Sprintf("No PEM encoded certificate found in %s", settings.caCertFile))
+			}
+		}
+
+		if settings.caCertPEM != "" && !pool.AppendCertsFromPEM([]byte(settings.caCertPEM)) {
+			diags.AddAttributeError(path.Root("ca_cert_pem"), "Invalid CA certificate", "No PEM encoded certificate found in ca_cert_pem")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if settings.clientCert != "" || settings.clientKey != "" {
+		if settings.clientCert == "" || settings.clientKey == "" {
+			diags.AddAttributeError(path.Root("client_cert"), "Incomplete client certificate", "client_cert and client_key must be set together")
+			return nil, diags
+		}
+
+		certPEM, err := pemOrFile(settings.clientCert)
+		if err != nil {
+			diags.AddAttributeError(path.Root("client_cert"), "Unable to read the client certificate", err.Error())
+		}
+
+		keyPEM, err := pemOrFile(settings.clientKey)
+		if err != nil {
+			diags.AddAttributeError(path.Root("client_key"), "Unable to read the client key", err.Error())
+		}
+
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			diags.AddAttributeError(path.Root("client_cert"), "Invalid client certificate", err.Error())
+			return nil, diags
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	transport := defaultTransport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, diags
+}
+
+// pemOrFile returns value itself when it holds PEM encoded data, otherwise it
+// is taken as the path of a file to read.
+func pemOrFile(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+
+	return os.ReadFile(value)
+}